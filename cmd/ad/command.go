@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
+)
+
+const (
+	cmdRun     = "run"
+	cmdMigrate = "migrate"
+	cleanAuto  = "auto"
+	cleanForce = "force"
+	cleanSkip  = "skip"
+)
+
+var (
+	errUnknownCommand = errors.New("unknown command, usage: ad-parser run [--daemon | --once] " +
+		"[--profile code] [--clean auto|force|skip] [--dry-run] [--output file] | ad-parser migrate")
+	errFlagsConflict = errors.New("flags --once and --daemon are mutually exclusive")
+	errCleanMode     = errors.New("clean mode must be auto, force or skip")
+	errCleanDaemon   = errors.New("flag --clean is supported only with --once")
+	errCleanDryRun   = errors.New("flag --clean force is not supported with --dry-run")
+)
+
+type command struct {
+	migrate  bool
+	once     bool
+	dryRun   bool
+	output   string
+	profiles []string
+	clean    parser.CleanMode
+}
+
+// listFlag collects repeated or comma separated flag values
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+// parseCommand parses command line, daemon mode is used when command is not set
+func parseCommand(args []string) (*command, error) {
+	if len(args) == 0 {
+		return &command{}, nil
+	}
+	if args[0] == cmdMigrate {
+		return parseMigrate(args)
+	}
+	if args[0] != cmdRun {
+		return nil, fmt.Errorf("%q: %w", args[0], errUnknownCommand)
+	}
+
+	var profiles listFlag
+	fs := flag.NewFlagSet(cmdRun, flag.ContinueOnError)
+	once := fs.Bool("once", false, "parse profiles once and exit")
+	daemon := fs.Bool("daemon", false, "parse profiles endlessly every check time (default)")
+	clean := fs.String("clean", cleanAuto, "clean step of once mode: auto, force or skip")
+	dryRun := fs.Bool("dry-run", false, "parse without storage, write ads as JSON Lines to output")
+	output := fs.String("output", "", "output file of dry run, stdout is used by default")
+	fs.Var(&profiles, "profile", "profile code, may be repeated or comma separated (default from config)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+
+	if *once && *daemon {
+		return nil, errFlagsConflict
+	}
+
+	cmd := &command{
+		once:     *once,
+		dryRun:   *dryRun,
+		output:   *output,
+		profiles: profiles,
+	}
+
+	switch *clean {
+	case cleanAuto:
+		cmd.clean = parser.CleanAuto
+	case cleanForce:
+		cmd.clean = parser.CleanForce
+	case cleanSkip:
+		cmd.clean = parser.CleanSkip
+	default:
+		return nil, errCleanMode
+	}
+
+	if !cmd.once && cmd.clean != parser.CleanAuto {
+		return nil, errCleanDaemon
+	}
+
+	if cmd.dryRun {
+		if cmd.clean == parser.CleanForce {
+			return nil, errCleanDryRun
+		}
+		cmd.clean = parser.CleanSkip
+	}
+
+	return cmd, nil
+}
+
+// parseMigrate parses migrate command, it applies schema migrations of storage
+// and sets source id of ads saved before it
+func parseMigrate(args []string) (*command, error) {
+	fs := flag.NewFlagSet(cmdMigrate, flag.ContinueOnError)
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("%q: %w", fs.Arg(0), errUnknownCommand)
+	}
+
+	return &command{
+		migrate: true,
+	}, nil
+}
+
+// checkProfiles checks that profiles of command are registered, it is called
+// after profiles of mappings and sources of config are registered
+func (c *command) checkProfiles() error {
+	for _, code := range c.profiles {
+		if _, ok := profile.ByCode(code); !ok {
+			return fmt.Errorf("profile %q: %w", code, model.ErrProfileNotFound)
+		}
+	}
+
+	return nil
+}
+
+func printSummary(w io.Writer, summaries []*parser.Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PROFILE\tSEARCHED\tSAVED\tERRORS\tLAST PAGE\tDURATION\tSTATUS")
+	for _, s := range summaries {
+		status := "ok"
+		if s.Err != nil {
+			status = s.Err.Error()
+		} else if s.Failed() {
+			status = "failed"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%t\t%s\t%s\n",
+			s.Code, s.Searched, s.Saved, s.Errors, s.LastPage, s.Duration.Round(time.Millisecond), status)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/model"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want *command
+	}{
+		{"daemon by default", nil, &command{}},
+		{"run", []string{"run"}, &command{clean: parser.CleanAuto}},
+		{"daemon", []string{"run", "--daemon"}, &command{clean: parser.CleanAuto}},
+		{"daemon profile", []string{"run", "--daemon", "--profile", "kufar"},
+			&command{profiles: []string{"kufar"}, clean: parser.CleanAuto}},
+		{"once profiles", []string{"run", "--once", "--profile", "kufar, realt", "--profile", "onliner"},
+			&command{once: true, profiles: []string{"kufar", "realt", "onliner"}, clean: parser.CleanAuto}},
+		{"once clean force", []string{"run", "--once", "--clean", "force"},
+			&command{once: true, clean: parser.CleanForce}},
+		{"once clean skip", []string{"run", "--once", "--clean=skip"},
+			&command{once: true, clean: parser.CleanSkip}},
+		{"dry run skips clean", []string{"run", "--once", "--dry-run"},
+			&command{once: true, dryRun: true, clean: parser.CleanSkip}},
+		{"dry run output", []string{"run", "--dry-run", "--output", "ads.jsonl"},
+			&command{dryRun: true, output: "ads.jsonl", clean: parser.CleanSkip}},
+		{"migrate", []string{"migrate"}, &command{migrate: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommand(tt.args)
+			if err != nil {
+				t.Fatalf("parse command: %s", err)
+			}
+			if err = got.checkProfiles(); err != nil {
+				t.Fatalf("check profiles: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("command %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommandRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{"once and daemon", []string{"run", "--once", "--daemon"}, errFlagsConflict},
+		{"unknown command", []string{"parse"}, errUnknownCommand},
+		{"migrate argument", []string{"migrate", "kufar"}, errUnknownCommand},
+		{"clean mode", []string{"run", "--once", "--clean", "always"}, errCleanMode},
+		{"clean of daemon", []string{"run", "--clean", "skip"}, errCleanDaemon},
+		{"clean force of dry run", []string{"run", "--once", "--dry-run", "--clean", "force"}, errCleanDryRun},
+		{"unknown profile", []string{"run", "--once", "--profile", "kufar,avito"}, model.ErrProfileNotFound},
+		{"help", []string{"run", "--help"}, flag.ErrHelp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := parseCommand(tt.args)
+			if err == nil {
+				err = cmd.checkProfiles()
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
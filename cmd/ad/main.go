@@ -2,55 +2,164 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/handler"
 	"github.com/sku4/ad-parser/internal/repository"
+	"github.com/sku4/ad-parser/internal/server"
 	"github.com/sku4/ad-parser/internal/service"
+	"github.com/sku4/ad-parser/internal/service/parser"
+	_ "github.com/sku4/ad-parser/internal/service/parser/sources"
+	"github.com/sku4/ad-parser/pkg/ad/postgres"
 	"github.com/sku4/ad-parser/pkg/logger"
 	"github.com/tarantool/go-tarantool/v2"
 	"github.com/tarantool/go-tarantool/v2/pool"
 )
 
+const (
+	exitFailure = 1
+	exitUsage   = 2
+)
+
 func main() {
-	// init config
+	os.Exit(run())
+}
+
+func run() int {
+	// parse command line
 	log := logger.Get()
-	cfg, err := configs.Init()
+	cmd, err := parseCommand(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
 	if err != nil {
-		log.Fatalf("error init config: %s", err)
+		log.Errorf("error parse command: %s", err)
+		return exitUsage
 	}
 
-	// init tarantool
-	conn, err := pool.Connect(cfg.Tarantool.Servers, tarantool.Opts{
-		Timeout:   cfg.Tarantool.Timeout,
-		Reconnect: cfg.Tarantool.ReconnectInterval,
-	})
+	// init config
+	cfg, err := configs.Init()
 	if err != nil {
-		log.Fatalf("error tarantool connection refused: %s", err)
+		log.Errorf("error init config: %s", err)
+		return exitFailure
 	}
-	defer func() {
-		errs := conn.Close()
-		for _, e := range errs {
-			log.Errorf("error close connection pool: %s", e)
+	if len(cmd.profiles) > 0 {
+		cfg.Profiles = cmd.profiles
+	}
+	if err = parser.RegisterMappings(cfg.Mappings); err != nil {
+		log.Errorf("error register mappings: %s", err)
+		return exitFailure
+	}
+	if err = parser.Declare(cfg.Sources); err != nil {
+		log.Errorf("error declare sources: %s", err)
+		return exitFailure
+	}
+	if err = cmd.checkProfiles(); err != nil {
+		log.Errorf("error parse command: %s", err)
+		return exitUsage
+	}
+
+	if cmd.dryRun {
+		cfg.Parser.DryRun = true
+	}
+	if cmd.migrate {
+		cfg.Parser.DryRun = false
+		cfg.Postgres.Migrate = true
+	}
+	if cmd.output != "" {
+		cfg.Parser.DryRunOutput = cmd.output
+	}
+
+	// init storage
+	var conn *pool.ConnectionPool
+	var checker handler.Checker
+	var repos *repository.Repository
+	switch {
+	case cfg.Parser.DryRun:
+		output, errOutput := openOutput(cfg.Parser.DryRunOutput)
+		if errOutput != nil {
+			log.Errorf("error open dry run output: %s", errOutput)
+			return exitFailure
 		}
-	}()
+		defer func() {
+			if errClose := output.Close(); errClose != nil {
+				log.Errorf("error close dry run output: %s", errClose)
+			}
+		}()
+		repos = repository.NewSinkRepository(output)
+	case cfg.Storage == configs.StorageMemory:
+		log.Info("Ads are stored in memory")
+		repos = repository.NewMemoryRepository()
+	case cfg.Storage == configs.StorageTarantool || cfg.Storage == "":
+		conn, err = pool.Connect(cfg.Tarantool.Servers, tarantool.Opts{
+			Timeout:   cfg.Tarantool.Timeout,
+			Reconnect: cfg.Tarantool.ReconnectInterval,
+		})
+		if err != nil {
+			log.Errorf("error tarantool connection refused: %s", err)
+			return exitFailure
+		}
+		defer func() {
+			errs := conn.Close()
+			for _, e := range errs {
+				log.Errorf("error close connection pool: %s", e)
+			}
+		}()
+		checker = handler.NewTarantoolChecker(conn)
+		repos = repository.NewRepository(conn, cfg)
+	case cfg.Storage == configs.StoragePostgres:
+		pgConn, errPg := connectPostgres(context.Background(), cfg.Postgres)
+		if errPg != nil {
+			log.Errorf("error postgres connection: %s", errPg)
+			return exitFailure
+		}
+		defer pgConn.Close()
+		checker = handler.NewPostgresChecker(pgConn)
+		repos = repository.NewPostgresRepository(pgConn, cfg)
+	default:
+		log.Errorf("error unknown storage '%s'", cfg.Storage)
+		return exitFailure
+	}
 
 	// init context
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 	ctx = configs.Set(ctx, cfg)
 
-	repos := repository.NewRepository(conn)
 	services := service.NewService(repos)
+
+	if cmd.migrate {
+		return migrate(ctx, services)
+	}
+
+	if cmd.once {
+		return runOnce(ctx, services, cmd, cfg)
+	}
+
+	handlers := handler.NewHandler(services, checker)
+	srv := server.NewServer(cfg.Server.Port, handlers.InitRoutes())
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
+	go func() {
+		if err := srv.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("error http server run: %s", err)
+		}
+	}()
+
 	log.Infof("App Started")
 
 	go func() {
-		if err = services.Parser.Run(ctx); err != nil {
+		if err := services.Parser.Run(ctx); err != nil {
 			log.Errorf("error parser run: %s", err)
 		}
 	}()
@@ -67,10 +176,113 @@ func main() {
 		log.Info("Parser stopped")
 	}
 
-	errs := conn.CloseGraceful()
-	for _, e := range errs {
-		log.Errorf("error close graceful connection pool: %s", e)
+	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+	if err = srv.Shutdown(ctxShutdown); err != nil {
+		log.Errorf("error http server shutdown: %s", err)
+	}
+
+	if conn != nil {
+		errs := conn.CloseGraceful()
+		for _, e := range errs {
+			log.Errorf("error close graceful connection pool: %s", e)
+		}
 	}
 
 	log.Info("App Shutting Down")
+
+	return 0
+}
+
+// runOnce parses profiles once, prints summary and returns exit code
+func runOnce(ctx context.Context, services *service.Service, cmd *command, cfg *configs.Config) int {
+	log := logger.Get()
+
+	summaries, err := services.Parser.RunOnce(ctx, cmd.profiles, cmd.clean)
+	if err != nil {
+		log.Errorf("error parser run once: %s", err)
+		return exitFailure
+	}
+
+	// summary must not be mixed with ads written to stdout
+	summaryOutput := os.Stdout
+	if cfg.Parser.DryRun && (cfg.Parser.DryRunOutput == "" || cfg.Parser.DryRunOutput == "-") {
+		summaryOutput = os.Stderr
+	}
+	if err = printSummary(summaryOutput, summaries); err != nil {
+		log.Errorf("error print summary: %s", err)
+	}
+
+	for _, s := range summaries {
+		if s.Failed() {
+			return exitFailure
+		}
+	}
+
+	return 0
+}
+
+// migrate sets source id of ads saved before it and returns exit code
+func migrate(ctx context.Context, services *service.Service) int {
+	log := logger.Get()
+
+	cnt, err := services.Parser.MigrateSourceIDs(ctx)
+	if err != nil {
+		log.Errorf("error migrate source ids: %s", err)
+		return exitFailure
+	}
+	log.Infof("Migration is finished, source id is set to %d ads", cnt)
+
+	return 0
+}
+
+// connectPostgres creates pool of connections and applies migrations when it is enabled
+func connectPostgres(ctx context.Context, cfg configs.Postgres) (*pgxpool.Pool, error) {
+	pgCfg, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConns > 0 {
+		pgCfg.MaxConns = cfg.MaxConns
+	}
+
+	pgConn, err := pgxpool.NewWithConfig(ctx, pgCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pgConn.Ping(ctx); err != nil {
+		pgConn.Close()
+		return nil, err
+	}
+
+	if cfg.Migrate {
+		applied, errMigrate := postgres.Migrate(ctx, pgConn)
+		if errMigrate != nil {
+			pgConn.Close()
+			return nil, errMigrate
+		}
+		for _, version := range applied {
+			logger.Get().Infof("Postgres migration %s applied", version)
+		}
+	}
+
+	return pgConn, nil
+}
+
+// openOutput opens dry run output file, stdout is used when path is empty
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	return os.Create(filepath.Clean(path))
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
 }
@@ -2,15 +2,107 @@ package configs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/sku4/ad-parser/pkg/ad/profile"
 	"github.com/spf13/viper"
 )
 
+const (
+	defaultListing = "sale"
+)
+
+// ErrRegionLocality is returned for region without locality of profile searching by locality
+var ErrRegionLocality = errors.New("region without locality")
+
+// defaultPropertyTypes are parsed when property types are not configured
+var defaultPropertyTypes = []string{"flat", "house"}
+
+// storages of ads
+const (
+	StorageTarantool = "tarantool"
+	StorageMemory    = "memory"
+	StoragePostgres  = "postgres"
+)
+
 type Config struct {
-	Profiles  []string `mapstructure:"profiles"`
-	Parser    `mapstructure:"parser"`
-	Tarantool `mapstructure:"tarantool"`
+	Profiles    []string            `mapstructure:"profiles"`
+	Sources     []Source            `mapstructure:"sources"`
+	Mappings    []string            `mapstructure:"mappings"`
+	Storage     string              `mapstructure:"storage"`
+	Regions     map[string][]Region `mapstructure:"regions"`
+	Parser      `mapstructure:"parser"`
+	Tarantool   `mapstructure:"tarantool"`
+	Postgres    `mapstructure:"postgres"`
+	Server      `mapstructure:"server"`
+	StreetCache `mapstructure:"street_cache"`
+	Dedup       `mapstructure:"dedup"`
+}
+
+// Source declares profile in registry or replaces name, base url and enabled state of registered one
+type Source struct {
+	ID      uint16 `mapstructure:"id"`
+	Code    string `mapstructure:"code"`
+	Name    string `mapstructure:"name"`
+	BaseURL string `mapstructure:"base_url"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
+type Region struct {
+	Name     string   `mapstructure:"name"`
+	Bbox     Bbox     `mapstructure:"bbox"`
+	Locality string   `mapstructure:"locality"`
+	Cities   []string `mapstructure:"cities"`
+}
+
+// Bbox is bounding box of region by left bottom and right top points
+type Bbox struct {
+	LbLat  float64 `mapstructure:"lb_lat"`
+	LbLong float64 `mapstructure:"lb_long"`
+	RtLat  float64 `mapstructure:"rt_lat"`
+	RtLong float64 `mapstructure:"rt_long"`
+}
+
+var (
+	DefaultRegion = Region{
+		Name: "minsk",
+		Bbox: Bbox{
+			LbLat:  53.822171699379794,
+			LbLong: 27.36090453127423,
+			RtLat:  53.97823316350124,
+			RtLong: 27.73193546111799,
+		},
+		Locality: "country-belarus~province-minsk~locality-minsk",
+		Cities:   []string{"Минск", "Беларусь", "Minsk"},
+	}
+)
+
+// ProfileRegions returns regions of profile, default region is returned when regions not configured
+func (c *Config) ProfileRegions(code string) []Region {
+	if c == nil || len(c.Regions[code]) == 0 {
+		return []Region{DefaultRegion}
+	}
+
+	return c.Regions[code]
+}
+
+// Validate checks regions of profiles, profile which searches by locality
+// must have locality of each region, empty locality is search of the whole country
+func (c *Config) Validate() error {
+	for code, regions := range c.Regions {
+		if p, ok := profile.ByCode(code); !ok || !p.Locality {
+			continue
+		}
+		for _, region := range regions {
+			if region.Locality == "" {
+				return fmt.Errorf("%w: region %q of profile %s", ErrRegionLocality, region.Name, code)
+			}
+		}
+	}
+
+	return nil
 }
 
 type Parser struct {
@@ -18,6 +110,66 @@ type Parser struct {
 	TooManyReqLimit     int           `mapstructure:"too_many_requests_limit"`
 	DownloadWorkerCount int           `mapstructure:"download_worker_count"`
 	CleanTime           time.Duration `mapstructure:"clean_time"`
+	StuckTime           time.Duration `mapstructure:"stuck_time"`
+	Incremental         bool          `mapstructure:"incremental"`
+	SaveBatchSize       int           `mapstructure:"save_batch_size"`
+	SaveBatchTime       time.Duration `mapstructure:"save_batch_time"`
+	DryRun              bool          `mapstructure:"dry_run"`
+	DryRunOutput        string        `mapstructure:"dry_run_output"`
+	Listings            []string      `mapstructure:"listings"`
+	PropertyTypes       []string      `mapstructure:"property_types"`
+	HTTP                HTTP          `mapstructure:"http"`
+}
+
+type HTTP struct {
+	Timeout    time.Duration `mapstructure:"timeout"`
+	Retries    int           `mapstructure:"retries"`
+	BackoffMin time.Duration `mapstructure:"backoff_min"`
+	BackoffMax time.Duration `mapstructure:"backoff_max"`
+	Proxies    []string      `mapstructure:"proxies"`
+	UserAgents []string      `mapstructure:"user_agents"`
+	RateLimit  RateLimit     `mapstructure:"rate_limit"`
+}
+
+type RateLimit struct {
+	RPS      float64 `mapstructure:"rps"`
+	MinRPS   float64 `mapstructure:"min_rps"`
+	Burst    int     `mapstructure:"burst"`
+	Decrease float64 `mapstructure:"decrease"`
+	Increase float64 `mapstructure:"increase"`
+}
+
+// ListingEnabled reports whether ads of the listing type must be parsed,
+// only sale listings are parsed when listings are not configured
+func (p Parser) ListingEnabled(code string) bool {
+	if len(p.Listings) == 0 {
+		return code == defaultListing
+	}
+
+	for _, l := range p.Listings {
+		if l == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PropertyEnabled reports whether ads of the property type must be parsed,
+// only flats and houses are parsed when property types are not configured
+func (p Parser) PropertyEnabled(code string) bool {
+	propertyTypes := p.PropertyTypes
+	if len(propertyTypes) == 0 {
+		propertyTypes = defaultPropertyTypes
+	}
+
+	for _, t := range propertyTypes {
+		if t == code {
+			return true
+		}
+	}
+
+	return false
 }
 
 type Tarantool struct {
@@ -28,6 +180,32 @@ type Tarantool struct {
 	ReconnectInterval time.Duration `mapstructure:"reconnect_interval"`
 }
 
+type Postgres struct {
+	DSN      string `mapstructure:"dsn"`
+	MaxConns int32  `mapstructure:"max_conns"`
+	Migrate  bool   `mapstructure:"migrate"`
+}
+
+// StreetCache is cache of street ids by names, not resolved names are kept for negative ttl
+type StreetCache struct {
+	Size        int           `mapstructure:"size"`
+	TTL         time.Duration `mapstructure:"ttl"`
+	NegativeTTL time.Duration `mapstructure:"negative_ttl"`
+}
+
+// Dedup is matching of ads of the same apartment from different profiles,
+// area tolerance is relative difference of areas, distance is in meters
+type Dedup struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	AreaTolerance float64 `mapstructure:"area_tolerance"`
+	Distance      float64 `mapstructure:"distance"`
+}
+
+type Server struct {
+	Port            int           `mapstructure:"port"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
 func Init() (*Config, error) {
 	mainViper := viper.New()
 	mainViper.AddConfigPath("configs")
@@ -41,6 +219,10 @@ func Init() (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -0,0 +1,25 @@
+package configs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		regions map[string][]Region
+		err     error
+	}{
+		{"not configured", nil, nil},
+		{"kufar with locality", map[string][]Region{"kufar": {DefaultRegion}}, nil},
+		{"kufar without locality", map[string][]Region{"kufar": {{Name: "brest"}}}, ErrRegionLocality},
+		{"realt without locality", map[string][]Region{"realt": {{Name: "brest"}}}, nil},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Regions: tt.regions}
+		if err := cfg.Validate(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: validate error %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tarantool/go-tarantool/v2/pool"
+)
+
+const (
+	pingTimeout = time.Second * 3
+)
+
+// Checker reports whether storage is connected in mode of tarantool pool,
+// storage without replicas is checked the same way in any mode
+type Checker interface {
+	Connected(ctx context.Context, mode pool.Mode) bool
+}
+
+// TarantoolChecker checks connections of tarantool pool
+type TarantoolChecker struct {
+	conn pool.Pooler
+}
+
+func NewTarantoolChecker(conn pool.Pooler) *TarantoolChecker {
+	return &TarantoolChecker{
+		conn: conn,
+	}
+}
+
+func (c *TarantoolChecker) Connected(_ context.Context, mode pool.Mode) bool {
+	ok, err := c.conn.ConnectedNow(mode)
+
+	return err == nil && ok
+}
+
+// PostgresChecker pings postgres pool
+type PostgresChecker struct {
+	conn *pgxpool.Pool
+}
+
+func NewPostgresChecker(conn *pgxpool.Pool) *PostgresChecker {
+	return &PostgresChecker{
+		conn: conn,
+	}
+}
+
+func (c *PostgresChecker) Connected(ctx context.Context, _ pool.Mode) bool {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	return c.conn.Ping(ctx) == nil
+}
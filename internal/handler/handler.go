@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sku4/ad-parser/internal/service"
+)
+
+type Handler struct {
+	services *service.Service
+	checker  Checker
+}
+
+// NewHandler creates handler, nil checker is used when ads are not stored in database
+func NewHandler(services *service.Service, checker Checker) *Handler {
+	return &Handler{
+		services: services,
+		checker:  checker,
+	}
+}
+
+func (h *Handler) InitRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", h.health)
+	mux.HandleFunc("/readyz", h.ready)
+
+	return mux
+}
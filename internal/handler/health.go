@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/pkg/logger"
+	"github.com/tarantool/go-tarantool/v2/pool"
+)
+
+type healthResp struct {
+	Storage  bool             `json:"storage"`
+	Profiles []*parser.Status `json:"profiles"`
+}
+
+type readyResp struct {
+	RW bool `json:"rw"`
+	RO bool `json:"ro"`
+}
+
+// health fails when storage is unreachable or some profile is stuck
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	resp := healthResp{
+		Storage:  h.connected(r.Context(), pool.ANY),
+		Profiles: h.services.Parser.Status(),
+	}
+
+	status := http.StatusOK
+	if !resp.Storage {
+		status = http.StatusServiceUnavailable
+	}
+	for _, s := range resp.Profiles {
+		if s.Stuck {
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	h.writeJSON(w, status, resp)
+}
+
+// ready fails when connection to rw or ro instance is lost
+func (h *Handler) ready(w http.ResponseWriter, r *http.Request) {
+	resp := readyResp{
+		RW: h.connected(r.Context(), pool.RW),
+		RO: h.connected(r.Context(), pool.RO),
+	}
+
+	status := http.StatusOK
+	if !resp.RW || !resp.RO {
+		status = http.StatusServiceUnavailable
+	}
+
+	h.writeJSON(w, status, resp)
+}
+
+// connected reports whether storage is connected, storage is not used in dry run and in memory
+func (h *Handler) connected(ctx context.Context, mode pool.Mode) bool {
+	if h.checker == nil {
+		return true
+	}
+
+	return h.checker.Connected(ctx, mode)
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Get().Errorf("error write response: %s", err)
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tarantool/go-tarantool/v2/pool"
+)
+
+type checker bool
+
+func (c checker) Connected(context.Context, pool.Mode) bool {
+	return bool(c)
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker Checker
+		status  int
+	}{
+		{"without storage", nil, http.StatusOK},
+		{"connected", checker(true), http.StatusOK},
+		{"disconnected", checker(false), http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		NewHandler(nil, tt.checker).InitRoutes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
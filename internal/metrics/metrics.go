@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace    = "ad_parser"
+	labelProfile = "profile"
+)
+
+var (
+	PagesSearched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pages_searched_total",
+		Help:      "Number of searched pages.",
+	}, []string{labelProfile})
+	AdsFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ads_found_total",
+		Help:      "Number of ads found on search pages.",
+	}, []string{labelProfile})
+	AdsSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ads_saved_total",
+		Help:      "Number of ads saved to storage.",
+	}, []string{labelProfile})
+	DownloadErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "download_errors_total",
+		Help:      "Number of ad download errors.",
+	}, []string{labelProfile})
+	SaveErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "save_errors_total",
+		Help:      "Number of ad save errors.",
+	}, []string{labelProfile})
+	TooManyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "too_many_requests_total",
+		Help:      "Number of too many requests responses.",
+	}, []string{labelProfile})
+	CleanedTuples = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cleaned_tuples_total",
+		Help:      "Number of outdated ads removed from storage.",
+	}, []string{labelProfile})
+	SearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "search_duration_seconds",
+		Help:      "Latency of search page requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{labelProfile})
+	DownloadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "download_duration_seconds",
+		Help:      "Latency of ad download requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{labelProfile})
+	LastSuccessRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_success_run_timestamp_seconds",
+		Help:      "Unix time of the last run which reached the last search page.",
+	}, []string{labelProfile})
+	StreetCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "street_cache_hits_total",
+		Help:      "Number of street names resolved by cache.",
+	})
+	StreetCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "street_cache_misses_total",
+		Help:      "Number of street names missed in cache.",
+	})
+)
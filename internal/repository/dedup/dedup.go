@@ -0,0 +1,155 @@
+package dedup
+
+import (
+	"context"
+	"math"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/model"
+	clientModel "github.com/sku4/ad-parser/pkg/ad/model"
+)
+
+const (
+	defaultAreaTolerance = 0.05
+	defaultDistance      = 150
+	earthRadius          = 6371000
+)
+
+// FindFunc returns ads with the same key for each key
+type FindFunc func(ctx context.Context, keys []*clientModel.CandidateKey) ([][]*model.Ad, error)
+
+// Matcher clusters ads of the same apartment listed by different profiles, ad which is not matched
+// has group id of hash of its source key, matched ad takes the smallest group id of matched ads
+type Matcher struct {
+	enabled       bool
+	areaTolerance float64
+	distance      float64
+}
+
+func NewMatcher(cfg configs.Dedup) *Matcher {
+	if cfg.AreaTolerance <= 0 {
+		cfg.AreaTolerance = defaultAreaTolerance
+	}
+	if cfg.Distance <= 0 {
+		cfg.Distance = defaultDistance
+	}
+
+	return &Matcher{
+		enabled:       cfg.Enabled,
+		areaTolerance: cfg.AreaTolerance,
+		distance:      cfg.Distance,
+	}
+}
+
+// Group sets group id of ads, matched reports whether ad joined group of other ad,
+// ads without street, house, floor or rooms are not matched
+func (m *Matcher) Group(ctx context.Context, ads []*model.Ad, find FindFunc) (matched []bool, err error) {
+	matched = make([]bool, len(ads))
+	keys := make([]*clientModel.CandidateKey, 0, len(ads))
+	keyAds := make([]int, 0, len(ads))
+	for i, ad := range ads {
+		ad.GroupID = ad.SourceKey().Hash()
+		if !m.enabled {
+			continue
+		}
+		if key, ok := Key(ad); ok {
+			keys = append(keys, key)
+			keyAds = append(keyAds, i)
+		}
+	}
+
+	if len(keys) == 0 {
+		return matched, nil
+	}
+
+	candidates, err := find(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, i := range keyAds {
+		for _, c := range candidates[k] {
+			if !m.Match(ads[i], c) {
+				continue
+			}
+			if !matched[i] || c.GroupID < ads[i].GroupID {
+				ads[i].GroupID = c.GroupID
+			}
+			matched[i] = true
+		}
+	}
+
+	return matched, nil
+}
+
+// Match reports whether candidate found by key of ad is the same apartment:
+// it is listed by other profile, area differs within tolerance and location within distance
+func (m *Matcher) Match(ad, c *model.Ad) bool {
+	if ad.Profile == c.Profile || c.GroupID == 0 {
+		return false
+	}
+
+	if ad.M2Main == nil || c.M2Main == nil {
+		return false
+	}
+	if math.Abs(*ad.M2Main-*c.M2Main) > m.areaTolerance*math.Max(*ad.M2Main, *c.M2Main) {
+		return false
+	}
+
+	if ad.LocLat != nil && ad.LocLong != nil && c.LocLat != nil && c.LocLong != nil &&
+		Distance(*ad.LocLat, *ad.LocLong, *c.LocLat, *c.LocLong) > m.distance {
+		return false
+	}
+
+	return true
+}
+
+// Key returns exact part of ad by which candidates are searched
+func Key(ad *model.Ad) (*clientModel.CandidateKey, bool) {
+	if ad.StreetID == nil || ad.House == nil || *ad.House == "" || ad.Floor == nil || ad.Rooms == nil {
+		return nil, false
+	}
+
+	return &clientModel.CandidateKey{
+		Profile:  ad.Profile,
+		StreetID: *ad.StreetID,
+		House:    *ad.House,
+		Floor:    *ad.Floor,
+		Rooms:    *ad.Rooms,
+		Listing:  uint8(ad.Listing),
+		Property: uint8(ad.Property),
+	}, true
+}
+
+// Distance returns distance in meters between two points
+func Distance(lat1, long1, lat2, long2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLong := (long2 - long1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLong/2)*math.Sin(dLong/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// FromTnt converts candidates of storage to ads, only fields used by matching are set
+func FromTnt(candidatesTnt [][]*clientModel.AdTnt) [][]*model.Ad {
+	candidates := make([][]*model.Ad, 0, len(candidatesTnt))
+	for _, adsTnt := range candidatesTnt {
+		ads := make([]*model.Ad, 0, len(adsTnt))
+		for _, adTnt := range adsTnt {
+			ads = append(ads, &model.Ad{
+				SourceID: adTnt.SourceID,
+				ExtID:    adTnt.ExtID,
+				LocLat:   adTnt.LocLat,
+				LocLong:  adTnt.LocLong,
+				M2Main:   adTnt.M2Main,
+				Profile:  adTnt.Profile,
+				GroupID:  adTnt.GroupID,
+			})
+		}
+		candidates = append(candidates, ads)
+	}
+
+	return candidates
+}
@@ -0,0 +1,116 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/model"
+	clientModel "github.com/sku4/ad-parser/pkg/ad/model"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func flat(sourceID string, profile uint16, m2, lat, long float64) *model.Ad {
+	ad := &model.Ad{
+		SourceID: sourceID,
+		Profile:  profile,
+		StreetID: ptr(uint64(7)),
+		House:    ptr("10"),
+		Floor:    ptr(uint8(3)),
+		Rooms:    ptr(uint8(2)),
+		M2Main:   ptr(m2),
+		LocLat:   ptr(lat),
+		LocLong:  ptr(long),
+	}
+	ad.GroupID = ad.SourceKey().Hash()
+
+	return ad
+}
+
+func groupOf(profile uint16, sourceID string) uint64 {
+	return model.SourceKey{Profile: profile, SourceID: sourceID}.Hash()
+}
+
+func TestMatch(t *testing.T) {
+	m := NewMatcher(configs.Dedup{Enabled: true, AreaTolerance: 0.05, Distance: 150})
+	ad := flat("1", 1, 50, 53.9, 27.5)
+	tests := []struct {
+		name string
+		c    *model.Ad
+		want bool
+	}{
+		{"same apartment", flat("2", 2, 51, 53.9005, 27.5005), true},
+		{"same profile", flat("2", 1, 50, 53.9, 27.5), false},
+		{"area out of tolerance", flat("2", 2, 55, 53.9, 27.5), false},
+		{"too far", flat("2", 2, 50, 53.91, 27.5), false},
+		{"no area", &model.Ad{SourceID: "2", Profile: 2, GroupID: groupOf(2, "2")}, false},
+		{"no location", &model.Ad{SourceID: "2", Profile: 2, GroupID: groupOf(2, "2"), M2Main: ptr(50.0)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Match(ad, tt.c); got != tt.want {
+				t.Errorf("match %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	stored := []*model.Ad{
+		flat("20", 2, 50, 53.9, 27.5),
+		flat("30", 3, 50, 53.9, 27.5),
+	}
+	stored[1].GroupID = stored[0].GroupID
+	find := func(_ context.Context, keys []*clientModel.CandidateKey) ([][]*model.Ad, error) {
+		candidates := make([][]*model.Ad, len(keys))
+		for i := range keys {
+			candidates[i] = stored
+		}
+		return candidates, nil
+	}
+
+	ads := []*model.Ad{
+		flat("1", 1, 50.5, 53.9, 27.5),
+		flat("2", 1, 80, 53.9, 27.5),
+		{SourceID: "3", Profile: 1},
+	}
+	m := NewMatcher(configs.Dedup{Enabled: true})
+	matched, err := m.Group(context.Background(), ads, find)
+	if err != nil {
+		t.Fatalf("group: %s", err)
+	}
+
+	want := []struct {
+		groupID uint64
+		matched bool
+	}{
+		{groupOf(2, "20"), true},
+		{groupOf(1, "2"), false},
+		{groupOf(1, "3"), false},
+	}
+	for i, w := range want {
+		if ads[i].GroupID != w.groupID || matched[i] != w.matched {
+			t.Errorf("ad %s: group %d matched %v, want %d %v",
+				ads[i].SourceID, ads[i].GroupID, matched[i], w.groupID, w.matched)
+		}
+	}
+
+	// disabled matcher keeps every ad in its own group
+	matched, err = NewMatcher(configs.Dedup{}).Group(context.Background(), ads, find)
+	if err != nil {
+		t.Fatalf("group disabled: %s", err)
+	}
+	if ads[0].GroupID != groupOf(1, "1") || matched[0] {
+		t.Errorf("disabled matcher grouped ad to %d", ads[0].GroupID)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// one degree of latitude is about 111 km
+	if d := Distance(53, 27, 54, 27); d < 111000 || d > 111400 {
+		t.Errorf("distance %f, want about 111200", d)
+	}
+}
@@ -0,0 +1,67 @@
+package ad
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/model"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+)
+
+// Ad writes normalized ads as JSON Lines instead of storage
+type Ad struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewAd(w io.Writer) *Ad {
+	return &Ad{
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (ad *Ad) Put(_ context.Context, modelAd *model.Ad, profileID uint16) error {
+	updated, err := datetime.NewDatetime(time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "put: time convert to datetime")
+	}
+
+	modelAd.Updated = updated
+	modelAd.Profile = profileID
+	modelAd.CalcPriceM2()
+
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+
+	if err = ad.enc.Encode(modelAd.Record()); err != nil {
+		return errors.Wrap(err, "put: encode")
+	}
+
+	return nil
+}
+
+func (ad *Ad) PutBatch(ctx context.Context, ads []*model.Ad, profileID uint16) []error {
+	errs := make([]error, len(ads))
+	for i, modelAd := range ads {
+		errs[i] = ad.Put(ctx, modelAd, profileID)
+	}
+
+	return errs
+}
+
+// Stored returns nothing, ads written to sink are never read back
+func (ad *Ad) Stored(context.Context, []string, uint16) ([]*model.Ad, error) {
+	return nil, nil
+}
+
+func (ad *Ad) MigrateSourceIDs(context.Context, model.SourceIDFunc) (uint64, error) {
+	return 0, nil
+}
+
+func (ad *Ad) Clean(context.Context, time.Time, uint16) (uint64, error) {
+	return 0, nil
+}
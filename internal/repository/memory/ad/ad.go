@@ -0,0 +1,249 @@
+package ad
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/repository/dedup"
+	"github.com/sku4/ad-parser/model"
+	clientModel "github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/sku4/ad-parser/pkg/logger"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+)
+
+const (
+	eventBufferSize = 100
+)
+
+// Ad keeps ads in memory, it is used by tests and local runs without tarantool
+type Ad struct {
+	mu          sync.RWMutex
+	ads         map[model.SourceKey]*model.Ad
+	streets     map[string]uint64
+	subscribers map[chan *model.Ad]struct{}
+	matcher     *dedup.Matcher
+}
+
+func NewAd() *Ad {
+	return &Ad{
+		ads:         make(map[model.SourceKey]*model.Ad),
+		streets:     make(map[string]uint64),
+		subscribers: make(map[chan *model.Ad]struct{}),
+		matcher:     dedup.NewMatcher(configs.Dedup{Enabled: true}),
+	}
+}
+
+func (ad *Ad) Put(ctx context.Context, modelAd *model.Ad, profileID uint16) error {
+	updated, err := datetime.NewDatetime(time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "put: time convert to datetime")
+	}
+
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+
+	// get street id
+	if modelAd.Street != nil && *modelAd.Street != "" {
+		streetID := ad.streetID(*modelAd.Street)
+		modelAd.StreetID = &streetID
+	}
+
+	modelAd.Updated = updated
+	modelAd.Profile = profileID
+
+	modelAd.CalcPriceM2()
+
+	// find ads of the same apartment from other profiles
+	matched, err := ad.matcher.Group(ctx, []*model.Ad{modelAd}, ad.candidates)
+	if err != nil {
+		return errors.Wrap(err, "put: candidates")
+	}
+
+	stored := *modelAd
+	key := modelAd.SourceKey()
+	if old, ok := ad.ads[key]; ok {
+		// if ad exists - keep create time and url as tarantool update does,
+		// photos and detail fields are kept when detail page is not loaded
+		stored.Created = old.Created
+		stored.URL = old.URL
+		stored.KeepDetail(old)
+		ad.ads[key] = &stored
+
+		return nil
+	}
+
+	ad.ads[key] = &stored
+
+	// send event as put new ad, ad of known apartment is not announced again
+	if !matched[0] {
+		ad.notify(&stored)
+	}
+
+	return nil
+}
+
+func (ad *Ad) PutBatch(ctx context.Context, ads []*model.Ad, profileID uint16) []error {
+	errs := make([]error, len(ads))
+	for i, modelAd := range ads {
+		errs[i] = ad.Put(ctx, modelAd, profileID)
+	}
+
+	return errs
+}
+
+// Stored returns copies of saved ads of profile with the given source ids
+func (ad *Ad) Stored(_ context.Context, sourceIDs []string, profileID uint16) ([]*model.Ad, error) {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+
+	ads := make([]*model.Ad, 0, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		a, ok := ad.ads[model.SourceKey{Profile: profileID, SourceID: sourceID}]
+		if !ok {
+			continue
+		}
+		c := *a
+		ads = append(ads, &c)
+	}
+
+	return ads, nil
+}
+
+// MigrateSourceIDs does nothing, ads in memory are never saved without source id
+func (ad *Ad) MigrateSourceIDs(context.Context, model.SourceIDFunc) (uint64, error) {
+	return 0, nil
+}
+
+func (ad *Ad) Clean(_ context.Context, timeTo time.Time, profileID uint16) (uint64, error) {
+	log := logger.Get()
+
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+
+	var cntClean uint64
+	for key, a := range ad.ads {
+		if a.Profile != profileID || a.Updated == nil || !a.Updated.ToTime().Before(timeTo) {
+			continue
+		}
+		delete(ad.ads, key)
+		cntClean++
+	}
+
+	log.Infof("Clean %d ads before time %s for profile %d from memory",
+		cntClean, timeTo.Format(time.DateTime), profileID)
+
+	return cntClean, nil
+}
+
+// Get returns copy of ad by profile and source id
+func (ad *Ad) Get(profileID uint16, sourceID string) (*model.Ad, bool) {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+
+	a, ok := ad.ads[model.SourceKey{Profile: profileID, SourceID: sourceID}]
+	if !ok {
+		return nil, false
+	}
+	c := *a
+
+	return &c, true
+}
+
+// List returns copies of all ads ordered by profile and source id
+func (ad *Ad) List() []*model.Ad {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+
+	ads := make([]*model.Ad, 0, len(ad.ads))
+	for _, a := range ad.ads {
+		c := *a
+		ads = append(ads, &c)
+	}
+	sort.Slice(ads, func(i, j int) bool {
+		if ads[i].Profile != ads[j].Profile {
+			return ads[i].Profile < ads[j].Profile
+		}
+		return ads[i].SourceID < ads[j].SourceID
+	})
+
+	return ads
+}
+
+// Group returns copies of ads of the same apartment ordered by profile and source id
+func (ad *Ad) Group(groupID uint64) []*model.Ad {
+	ads := make([]*model.Ad, 0)
+	for _, a := range ad.List() {
+		if a.GroupID == groupID {
+			ads = append(ads, a)
+		}
+	}
+
+	return ads
+}
+
+// Subscribe returns channel of new ads, events are dropped when subscriber is slow
+// as tarantool broadcast does, cancel must be called to unsubscribe
+func (ad *Ad) Subscribe() (events <-chan *model.Ad, cancel func()) {
+	ch := make(chan *model.Ad, eventBufferSize)
+
+	ad.mu.Lock()
+	ad.subscribers[ch] = struct{}{}
+	ad.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			ad.mu.Lock()
+			delete(ad.subscribers, ch)
+			ad.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// streetID returns id of street by name, new id is assigned to unknown street
+func (ad *Ad) streetID(street string) uint64 {
+	name := strings.ToLower(strings.TrimSpace(street))
+	id, ok := ad.streets[name]
+	if !ok {
+		id = uint64(len(ad.streets) + 1)
+		ad.streets[name] = id
+	}
+
+	return id
+}
+
+// candidates returns ads with the same key, it is called under lock
+func (ad *Ad) candidates(_ context.Context, keys []*clientModel.CandidateKey) ([][]*model.Ad, error) {
+	candidates := make([][]*model.Ad, len(keys))
+	for _, a := range ad.ads {
+		key, ok := dedup.Key(a)
+		if !ok {
+			continue
+		}
+		for i, k := range keys {
+			if key.Profile != k.Profile && key.StreetID == k.StreetID && key.House == k.House &&
+				key.Floor == k.Floor && key.Rooms == k.Rooms && key.Listing == k.Listing &&
+				key.Property == k.Property {
+				candidates[i] = append(candidates[i], a)
+			}
+		}
+	}
+
+	return candidates, nil
+}
+
+func (ad *Ad) notify(modelAd *model.Ad) {
+	for ch := range ad.subscribers {
+		c := *modelAd
+		select {
+		case ch <- &c:
+		default:
+		}
+	}
+}
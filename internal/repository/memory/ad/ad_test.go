@@ -0,0 +1,277 @@
+package ad
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sku4/ad-parser/model"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+const (
+	profileKufar   = 1
+	profileOnliner = 2
+)
+
+func TestPutUpsert(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAd()
+	events, cancel := repo.Subscribe()
+	defer cancel()
+
+	created := mustDatetime(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	street := "улица Притыцкого"
+	m2 := 50.0
+	if err := repo.Put(ctx, &model.Ad{
+		SourceID: "1",
+		Created:  created,
+		URL:      "https://example.com/1",
+		Street:   &street,
+		Price:    mustDecimal(t, "50000"),
+		M2Main:   &m2,
+	}, profileKufar); err != nil {
+		t.Fatalf("put new ad: %s", err)
+	}
+
+	select {
+	case e := <-events:
+		if e.SourceID != "1" {
+			t.Fatalf("event of ad %s, want 1", e.SourceID)
+		}
+	default:
+		t.Fatal("no event of new ad")
+	}
+
+	sameStreet := " Улица Притыцкого "
+	if err := repo.Put(ctx, &model.Ad{
+		SourceID: "1",
+		URL:      "https://example.com/changed",
+		Street:   &sameStreet,
+		Price:    mustDecimal(t, "45000"),
+		M2Main:   &m2,
+	}, profileKufar); err != nil {
+		t.Fatalf("put existed ad: %s", err)
+	}
+
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event of updated ad %s", e.SourceID)
+	default:
+	}
+
+	ads := repo.List()
+	if len(ads) != 1 {
+		t.Fatalf("got %d ads, want 1", len(ads))
+	}
+	got := ads[0]
+	if got.URL != "https://example.com/1" {
+		t.Errorf("url %s is changed by update", got.URL)
+	}
+	if got.Created == nil || !got.Created.ToTime().Equal(created.ToTime()) {
+		t.Errorf("create time %v is changed by update", got.Created)
+	}
+	if got.Price.String() != "45000" {
+		t.Errorf("price %s, want 45000", got.Price)
+	}
+	if got.PriceM2 == nil || got.PriceM2.String() != "900" {
+		t.Errorf("price m2 %v, want 900", got.PriceM2)
+	}
+	if got.StreetID == nil || *got.StreetID != 1 {
+		t.Errorf("street id %v, want 1", got.StreetID)
+	}
+	if got.Profile != profileKufar || got.Updated == nil {
+		t.Errorf("profile %d and update time %v are not set", got.Profile, got.Updated)
+	}
+}
+
+func TestClean(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAd()
+
+	for _, a := range []struct {
+		sourceID string
+		profile  uint16
+	}{
+		{"1", profileKufar},
+		{"2", profileKufar},
+		{"1", profileOnliner},
+	} {
+		if err := repo.Put(ctx, &model.Ad{SourceID: a.sourceID}, a.profile); err != nil {
+			t.Fatalf("put ad %s: %s", a.sourceID, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		timeTo  time.Time
+		profile uint16
+		want    uint64
+		left    int
+	}{
+		{"before put", time.Now().Add(-time.Hour), profileKufar, 0, 3},
+		{"other profile is kept", time.Now().Add(time.Hour), profileKufar, 2, 1},
+		{"cleaned profile", time.Now().Add(time.Hour), profileKufar, 0, 1},
+		{"last profile", time.Now().Add(time.Hour), profileOnliner, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cnt, err := repo.Clean(ctx, tt.timeTo, tt.profile)
+			if err != nil {
+				t.Fatalf("clean: %s", err)
+			}
+			if cnt != tt.want {
+				t.Errorf("cleaned %d ads, want %d", cnt, tt.want)
+			}
+			if left := len(repo.List()); left != tt.left {
+				t.Errorf("left %d ads, want %d", left, tt.left)
+			}
+		})
+	}
+}
+
+func TestStored(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAd()
+
+	for _, sourceID := range []string{"1", "2"} {
+		if err := repo.Put(ctx, &model.Ad{SourceID: sourceID}, profileKufar); err != nil {
+			t.Fatalf("put ad %s: %s", sourceID, err)
+		}
+	}
+
+	ads, err := repo.Stored(ctx, []string{"2", "3"}, profileKufar)
+	if err != nil {
+		t.Fatalf("stored: %s", err)
+	}
+	if len(ads) != 1 || ads[0].SourceID != "2" || ads[0].Updated == nil {
+		t.Fatalf("expected saved ad 2 only, got %+v", ads)
+	}
+
+	ads, err = repo.Stored(ctx, []string{"1", "2"}, profileOnliner)
+	if err != nil {
+		t.Fatalf("stored: %s", err)
+	}
+	if len(ads) != 0 {
+		t.Fatalf("expected no ads of other profile, got %d", len(ads))
+	}
+}
+
+func TestPutKeepDetail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAd()
+
+	description := "detail"
+	if err := repo.Put(ctx, &model.Ad{
+		SourceID:    "1",
+		Photos:      []string{"1.jpg", "2.jpg"},
+		Description: &description,
+		Detailed:    true,
+	}, profileKufar); err != nil {
+		t.Fatalf("put detailed ad: %s", err)
+	}
+
+	// detail page failed, search data has the first photo only
+	if err := repo.Put(ctx, &model.Ad{
+		SourceID: "1",
+		Photos:   []string{"1.jpg"},
+		Price:    mustDecimal(t, "45000"),
+	}, profileKufar); err != nil {
+		t.Fatalf("put ad without detail: %s", err)
+	}
+
+	ads, err := repo.Stored(ctx, []string{"1"}, profileKufar)
+	if err != nil {
+		t.Fatalf("stored: %s", err)
+	}
+	ad := ads[0]
+	if len(ad.Photos) != 2 || ad.Description == nil || *ad.Description != description {
+		t.Fatalf("detail is not kept: photos %v, description %v", ad.Photos, ad.Description)
+	}
+	if ad.Price == nil || ad.Price.String() != "45000" {
+		t.Fatalf("price is not updated: %v", ad.Price)
+	}
+}
+
+func mustDatetime(t *testing.T, tm time.Time) *datetime.Datetime {
+	t.Helper()
+
+	dt, err := datetime.NewDatetime(tm)
+	if err != nil {
+		t.Fatalf("convert time: %s", err)
+	}
+
+	return dt
+}
+
+func mustDecimal(t *testing.T, s string) *decimal.Decimal {
+	t.Helper()
+
+	d, err := decimal.NewDecimalFromString(s)
+	if err != nil {
+		t.Fatalf("convert decimal: %s", err)
+	}
+
+	return d
+}
+
+func TestPutDuplicates(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAd()
+	events, cancel := repo.Subscribe()
+	defer cancel()
+
+	newAd := func(sourceID string, m2 float64, property model.PropertyType) *model.Ad {
+		street, house := "ул. Притыцкого", "10"
+		var floor, rooms uint8 = 3, 2
+		return &model.Ad{
+			SourceID: sourceID,
+			URL:      "https://example.com/" + sourceID,
+			Street:   &street,
+			House:    &house,
+			Floor:    &floor,
+			Rooms:    &rooms,
+			M2Main:   &m2,
+			Property: property,
+		}
+	}
+	newFlat := func(sourceID string, m2 float64) *model.Ad {
+		return newAd(sourceID, m2, model.PropertyFlat)
+	}
+
+	if err := repo.Put(ctx, newFlat("1", 50), profileKufar); err != nil {
+		t.Fatalf("put kufar ad: %s", err)
+	}
+	if err := repo.Put(ctx, newFlat("pk/1", 50.4), profileOnliner); err != nil {
+		t.Fatalf("put onliner ad: %s", err)
+	}
+	if err := repo.Put(ctx, newFlat("pk/2", 70), profileOnliner); err != nil {
+		t.Fatalf("put other flat: %s", err)
+	}
+	if err := repo.Put(ctx, newAd("pk/3", 50, model.PropertyOffice), profileOnliner); err != nil {
+		t.Fatalf("put office: %s", err)
+	}
+
+	kufarKey := model.SourceKey{Profile: profileKufar, SourceID: "1"}
+	group := repo.Group(kufarKey.Hash())
+	if len(group) != 2 || group[0].SourceID != "1" || group[1].SourceID != "pk/1" {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	otherKey := model.SourceKey{Profile: profileOnliner, SourceID: "pk/2"}
+	if a, _ := repo.Get(profileOnliner, "pk/2"); a.GroupID != otherKey.Hash() {
+		t.Errorf("other flat is in group %d, want %d", a.GroupID, otherKey.Hash())
+	}
+	officeKey := model.SourceKey{Profile: profileOnliner, SourceID: "pk/3"}
+	if a, _ := repo.Get(profileOnliner, "pk/3"); a.GroupID != officeKey.Hash() {
+		t.Errorf("office at address of flat is in group %d, want %d", a.GroupID, officeKey.Hash())
+	}
+
+	got := make([]string, 0)
+	for len(events) > 0 {
+		got = append(got, (<-events).SourceID)
+	}
+	if len(got) != 3 || got[0] != "1" || got[1] != "pk/2" || got[2] != "pk/3" {
+		t.Errorf("events of ads %v, want [1 pk/2 pk/3]", got)
+	}
+}
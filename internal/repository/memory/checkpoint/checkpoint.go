@@ -0,0 +1,52 @@
+package checkpoint
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sku4/ad-parser/model"
+)
+
+// Checkpoint keeps checkpoints of search in memory, search is resumed only until the service stops
+type Checkpoint struct {
+	mu          sync.RWMutex
+	checkpoints map[uint16]*model.Checkpoint
+}
+
+func NewCheckpoint() *Checkpoint {
+	return &Checkpoint{
+		checkpoints: make(map[uint16]*model.Checkpoint),
+	}
+}
+
+func (c *Checkpoint) Load(_ context.Context, profileID uint16) (*model.Checkpoint, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cp, ok := c.checkpoints[profileID]
+	if !ok {
+		return nil, nil
+	}
+	cpCopy := *cp
+
+	return &cpCopy, nil
+}
+
+func (c *Checkpoint) Save(_ context.Context, cp *model.Checkpoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cpCopy := *cp
+	c.checkpoints[cp.Profile] = &cpCopy
+
+	return nil
+}
+
+func (c *Checkpoint) Reset(_ context.Context, profileID uint16) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.checkpoints, profileID)
+
+	return nil
+}
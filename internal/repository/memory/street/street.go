@@ -0,0 +1,18 @@
+package street
+
+import (
+	"context"
+
+	"github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+// Street has no street types, streets of ads kept in memory or written to sink are not normalized by types
+type Street struct{}
+
+func NewStreet() *Street {
+	return &Street{}
+}
+
+func (s *Street) Types(context.Context) (map[uint8]*street.Type, error) {
+	return nil, nil
+}
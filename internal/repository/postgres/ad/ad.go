@@ -0,0 +1,367 @@
+package ad
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/repository/dedup"
+	"github.com/sku4/ad-parser/internal/repository/street"
+	"github.com/sku4/ad-parser/model"
+	clientModel "github.com/sku4/ad-parser/pkg/ad/model"
+	client "github.com/sku4/ad-parser/pkg/ad/postgres"
+	"github.com/sku4/ad-parser/pkg/logger"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+)
+
+const (
+	batchLimitMigrate = 1000
+	adColumns         = "ext_id, c_time, u_time, url, street_id, house, loc_lat, loc_long, " +
+		"price, price_m2, rooms, floor, floors, year, photos, m2_main, m2_living, m2_kitchen, " +
+		"bathroom, profile, listing, price_month, rent_period, owner, agency, region, group_id, source_id, " +
+		"description, seller, phone_hidden, material, ceiling_height, balcony, renovation, parking, " +
+		"property_type, m2_land"
+)
+
+type Ad struct {
+	conn    *pgxpool.Pool
+	client  *client.Client
+	streets *street.Cache
+	matcher *dedup.Matcher
+}
+
+func NewAd(conn *pgxpool.Pool, streets *street.Cache, matcher *dedup.Matcher) *Ad {
+	return &Ad{
+		conn:    conn,
+		client:  client.NewClient(conn),
+		streets: streets,
+		matcher: matcher,
+	}
+}
+
+func (ad *Ad) Put(ctx context.Context, modelAd *model.Ad, profileID uint16) error {
+	return ad.PutBatch(ctx, []*model.Ad{modelAd}, profileID)[0]
+}
+
+// PutBatch saves ads in one transaction, every ad is saved in its own savepoint
+// so failed ad does not roll back others
+func (ad *Ad) PutBatch(ctx context.Context, ads []*model.Ad, profileID uint16) []error {
+	errs := make([]error, len(ads))
+	if len(ads) == 0 {
+		return errs
+	}
+
+	updated, err := datetime.NewDatetime(time.Now().UTC())
+	if err != nil {
+		return fillErrors(errs, errors.Wrap(err, "put: time convert to datetime"))
+	}
+
+	// get street ids, every street is resolved once per batch
+	streetIDs := make(map[string]*uint64)
+	for i, modelAd := range ads {
+		if modelAd.Street == nil || *modelAd.Street == "" {
+			continue
+		}
+		streetID, ok := streetIDs[*modelAd.Street]
+		if !ok {
+			var errStreet error
+			streetID, errStreet = ad.streetID(ctx, *modelAd.Street)
+			if errStreet != nil {
+				errs[i] = errors.Wrap(errStreet, "put: street get id")
+				continue
+			}
+			streetIDs[*modelAd.Street] = streetID
+		}
+		modelAd.StreetID = streetID
+	}
+
+	for _, modelAd := range ads {
+		modelAd.Updated = updated
+		modelAd.Profile = profileID
+		modelAd.CalcPriceM2()
+	}
+
+	// find ads of the same apartment from other profiles
+	matched, err := ad.matcher.Group(ctx, ads, ad.candidates)
+	if err != nil {
+		return fillErrors(errs, errors.Wrap(err, "put: candidates"))
+	}
+
+	err = pgx.BeginFunc(ctx, ad.conn, func(tx pgx.Tx) error {
+		for i, modelAd := range ads {
+			if errs[i] != nil {
+				continue
+			}
+
+			errs[i] = pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+				return ad.put(ctx, sp, modelAd, updated, matched[i])
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fillErrors(errs, errors.Wrap(err, "put: commit"))
+	}
+
+	return errs
+}
+
+func (ad *Ad) Clean(ctx context.Context, timeTo time.Time, profileID uint16) (uint64, error) {
+	log := logger.Get()
+
+	// clean ads
+	cntClean, err := ad.client.AdsClean(ctx, timeTo, profileID)
+	if err != nil {
+		return 0, errors.Wrap(err, "clean: delete")
+	}
+
+	profileCode := ad.client.ProfileGetByID(ctx, profileID)
+
+	log.Infof("Clean %d rows before time %s for '%s' profile",
+		cntClean, timeTo.Format(time.DateTime), profileCode)
+
+	hits, misses := ad.streets.Stats()
+	log.Infof("Street cache: %d hits, %d misses", hits, misses)
+
+	return cntClean, nil
+}
+
+// Stored returns saved ads of profile with the given source ids, only update time and prices are set
+func (ad *Ad) Stored(ctx context.Context, sourceIDs []string, profileID uint16) ([]*model.Ad, error) {
+	adsTnt, err := ad.client.AdsBySource(ctx, profileID, sourceIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "stored: ads by source")
+	}
+
+	ads := make([]*model.Ad, 0, len(adsTnt))
+	for _, adTnt := range adsTnt {
+		ads = append(ads, &model.Ad{
+			SourceID:   adTnt.SourceID,
+			Updated:    adTnt.Updated,
+			Price:      adTnt.Price,
+			PriceMonth: adTnt.PriceMonth,
+			Profile:    adTnt.Profile,
+		})
+	}
+
+	return ads, nil
+}
+
+// MigrateSourceIDs sets source id of ads saved before it by url of ad and rehashes group id
+// of group keyed by ext id of ad, ads with url unknown by profile are skipped
+func (ad *Ad) MigrateSourceIDs(ctx context.Context, sourceID model.SourceIDFunc) (uint64, error) {
+	log := logger.Get()
+
+	var after, cnt, skipped uint64
+	for {
+		batch, err := ad.client.AdsWithoutSourceID(ctx, after, batchLimitMigrate)
+		if err != nil {
+			return cnt, errors.Wrap(err, "migrate source ids: select")
+		}
+		if len(batch) == 0 {
+			break
+		}
+		after = batch[len(batch)-1].ID
+
+		ads := make([]*clientModel.AdSourceTnt, 0, len(batch))
+		for _, a := range batch {
+			id, ok := sourceID(a.Profile, a.URL)
+			if !ok {
+				skipped++
+				continue
+			}
+			a.SourceID = id
+			a.GroupID = model.SourceKey{Profile: a.Profile, SourceID: id}.Hash()
+			ads = append(ads, a)
+		}
+		if err = ad.client.AdSetSourceIDs(ctx, ads); err != nil {
+			return cnt, errors.Wrap(err, "migrate source ids: update")
+		}
+		cnt += uint64(len(ads))
+
+		if len(batch) < batchLimitMigrate {
+			break
+		}
+	}
+
+	log.Infof("Source id is set to %d ads, %d ads with unknown url are skipped", cnt, skipped)
+
+	return cnt, nil
+}
+
+// streetID returns id of street from cache or resolves it by postgres,
+// nil id is returned when street is not resolved
+func (ad *Ad) streetID(ctx context.Context, name string) (*uint64, error) {
+	if streetID, ok := ad.streets.Get(name); ok {
+		return streetID, nil
+	}
+
+	streetGetID, err := ad.client.StreetGetID(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if streetGetID.Status != http.StatusOK {
+		ad.streets.AddNotFound(name)
+		return nil, nil
+	}
+	ad.streets.Add(name, streetGetID.ID)
+
+	return &streetGetID.ID, nil
+}
+
+func (ad *Ad) candidates(ctx context.Context, keys []*clientModel.CandidateKey) ([][]*model.Ad, error) {
+	candidates, err := ad.client.AdCandidates(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return dedup.FromTnt(candidates), nil
+}
+
+func (ad *Ad) put(ctx context.Context, tx pgx.Tx, modelAd *model.Ad, updated *datetime.Datetime,
+	matched bool) error {
+	var id uint64
+	var priceOld *string
+	err := tx.QueryRow(ctx, "SELECT id, price::text FROM ad WHERE profile = $1 AND source_id = $2 FOR UPDATE",
+		modelAd.Profile, modelAd.SourceID).Scan(&id, &priceOld)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// ad saved before source id is found by ext id and url, it gets source id by update
+		err = tx.QueryRow(ctx, `SELECT id, price::text FROM ad
+			WHERE ext_id = $1 AND source_id IS NULL AND profile = $2 AND url = $3 LIMIT 1 FOR UPDATE`,
+			int64(modelAd.ExtID), modelAd.Profile, modelAd.URL).Scan(&id, &priceOld)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ad.insert(ctx, tx, modelAd, matched)
+	}
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("put: source_id select %s", modelAd.SourceID))
+	}
+
+	// if ad exists - update all fields except create time and url,
+	// photos and detail fields are kept when detail page is not loaded
+	_, err = tx.Exec(ctx, `UPDATE ad SET u_time = $2, street_id = $3, house = $4,
+		loc_lat = $5, loc_long = $6, price = $7, price_m2 = $8, rooms = $9, floor = $10,
+		floors = $11, year = $12, photos = CASE WHEN $36 THEN $13 ELSE photos END,
+		m2_main = $14, m2_living = $15, m2_kitchen = $16,
+		bathroom = $17, listing = $18, price_month = $19, rent_period = $20, owner = $21,
+		agency = $22, region = $23, group_id = $24, source_id = $25,
+		description = CASE WHEN $36 THEN $26 ELSE description END,
+		seller = CASE WHEN $36 THEN $27 ELSE seller END,
+		phone_hidden = CASE WHEN $36 THEN $28 ELSE phone_hidden END,
+		material = CASE WHEN $36 THEN $29 ELSE material END,
+		ceiling_height = CASE WHEN $36 THEN $30 ELSE ceiling_height END,
+		balcony = CASE WHEN $36 THEN $31 ELSE balcony END,
+		renovation = CASE WHEN $36 THEN $32 ELSE renovation END,
+		parking = CASE WHEN $36 THEN $33 ELSE parking END,
+		property_type = $34, m2_land = $35
+		WHERE id = $1`,
+		id, client.Time(modelAd.Updated), modelAd.StreetID, modelAd.House,
+		modelAd.LocLat, modelAd.LocLong, client.Numeric(modelAd.Price), client.Numeric(modelAd.PriceM2),
+		modelAd.Rooms, modelAd.Floor, modelAd.Floors, modelAd.Year, photos(modelAd.Photos),
+		modelAd.M2Main, modelAd.M2Living, modelAd.M2Kitchen, modelAd.Bathroom, uint8(modelAd.Listing),
+		client.Numeric(modelAd.PriceMonth), rentPeriod(modelAd.RentPeriod), modelAd.Owner,
+		modelAd.Agency, modelAd.Region, int64(modelAd.GroupID), modelAd.SourceID, modelAd.Description,
+		seller(modelAd.Seller), modelAd.PhoneHidden, modelAd.Material, modelAd.CeilingHeight,
+		modelAd.Balcony, modelAd.Renovation, modelAd.Parking, uint8(modelAd.Property), modelAd.M2Land,
+		modelAd.Detailed)
+	if err != nil {
+		return errors.Wrap(err, "put: update")
+	}
+
+	// save price history if price was changed
+	old, err := client.ToDecimal(priceOld)
+	if err != nil {
+		return errors.Wrap(err, "put: old price")
+	}
+	if !model.PriceEqual(old, modelAd.Price) {
+		err = client.PricePut(ctx, tx, &clientModel.AdPriceTnt{
+			AdID:     id,
+			Created:  updated,
+			PriceOld: old,
+			PriceNew: modelAd.Price,
+			Profile:  modelAd.Profile,
+		})
+		if err != nil {
+			return errors.Wrap(err, "put: price history")
+		}
+	}
+
+	return nil
+}
+
+func (ad *Ad) insert(ctx context.Context, tx pgx.Tx, modelAd *model.Ad, matched bool) error {
+	//nolint:gosec
+	_, err := tx.Exec(ctx, "INSERT INTO ad ("+adColumns+`) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+		$14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28,
+		$29, $30, $31, $32, $33, $34, $35, $36, $37, $38)`,
+		int64(modelAd.ExtID), client.Time(modelAd.Created), client.Time(modelAd.Updated), modelAd.URL,
+		modelAd.StreetID, modelAd.House, modelAd.LocLat, modelAd.LocLong,
+		client.Numeric(modelAd.Price), client.Numeric(modelAd.PriceM2),
+		modelAd.Rooms, modelAd.Floor, modelAd.Floors, modelAd.Year, photos(modelAd.Photos),
+		modelAd.M2Main, modelAd.M2Living, modelAd.M2Kitchen, modelAd.Bathroom, modelAd.Profile,
+		uint8(modelAd.Listing), client.Numeric(modelAd.PriceMonth), rentPeriod(modelAd.RentPeriod),
+		modelAd.Owner, modelAd.Agency, modelAd.Region, int64(modelAd.GroupID), modelAd.SourceID,
+		modelAd.Description, seller(modelAd.Seller), modelAd.PhoneHidden, modelAd.Material,
+		modelAd.CeilingHeight, modelAd.Balcony, modelAd.Renovation, modelAd.Parking,
+		uint8(modelAd.Property), modelAd.M2Land)
+	if err != nil {
+		return errors.Wrap(err, "put: insert")
+	}
+
+	// ad of known apartment is not announced again
+	if matched {
+		return nil
+	}
+
+	// send notification as put new ad, it is delivered on commit
+	_, err = tx.Exec(ctx, "SELECT pg_notify($1, $2)",
+		clientModel.EventNewAd, strconv.FormatUint(uint64(modelAd.Profile), 10)+":"+modelAd.SourceID)
+	if err != nil {
+		return errors.Wrap(err, "put: notify")
+	}
+
+	return nil
+}
+
+func photos(p []string) []string {
+	if p == nil {
+		return []string{}
+	}
+
+	return p
+}
+
+func rentPeriod(rp *model.RentPeriod) *uint8 {
+	if rp == nil {
+		return nil
+	}
+	v := uint8(*rp)
+
+	return &v
+}
+
+func seller(st *model.SellerType) *uint8 {
+	if st == nil {
+		return nil
+	}
+	v := uint8(*st)
+
+	return &v
+}
+
+func fillErrors(errs []error, err error) []error {
+	for i := range errs {
+		if errs[i] == nil {
+			errs[i] = err
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,118 @@
+package ad
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/repository/dedup"
+	"github.com/sku4/ad-parser/internal/repository/street"
+	"github.com/sku4/ad-parser/model"
+	client "github.com/sku4/ad-parser/pkg/ad/postgres"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+const (
+	// dsnEnv is dsn of local postgres, test database is cleared by tests
+	dsnEnv       = "AD_PARSER_TEST_POSTGRES_DSN"
+	profileKufar = 1
+)
+
+func testConn(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("set %s to run tests against local postgres", dsnEnv)
+	}
+
+	ctx := context.Background()
+	conn, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %s", err)
+	}
+	t.Cleanup(conn.Close)
+
+	if _, err = client.Migrate(ctx, conn); err != nil {
+		t.Fatalf("migrate: %s", err)
+	}
+	if _, err = conn.Exec(ctx, "TRUNCATE ad, ad_price, subscription, street RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("truncate: %s", err)
+	}
+
+	return conn
+}
+
+func TestPutAndClean(t *testing.T) {
+	conn := testConn(t)
+	ctx := context.Background()
+	repo := NewAd(conn, street.NewCache(configs.StreetCache{}), dedup.NewMatcher(configs.Dedup{Enabled: true}))
+
+	street := "улица Притыцкого"
+	m2 := 50.0
+	price, _ := decimal.NewDecimalFromString("50000")
+	if err := repo.Put(ctx, &model.Ad{
+		SourceID: "1",
+		URL:      "https://example.com/1",
+		Street:   &street,
+		Price:    price,
+		M2Main:   &m2,
+		Listing:  model.ListingSale,
+	}, profileKufar); err != nil {
+		t.Fatalf("put new ad: %s", err)
+	}
+
+	priceNew, _ := decimal.NewDecimalFromString("45000.50")
+	if err := repo.Put(ctx, &model.Ad{
+		SourceID: "1",
+		URL:      "https://example.com/1",
+		Street:   &street,
+		Price:    priceNew,
+		M2Main:   &m2,
+		Listing:  model.ListingSale,
+	}, profileKufar); err != nil {
+		t.Fatalf("put existed ad: %s", err)
+	}
+
+	var id uint64
+	var streetID *uint64
+	var priceM2 string
+	err := conn.QueryRow(ctx, "SELECT id, street_id, price_m2::text FROM ad WHERE ext_id = 1").
+		Scan(&id, &streetID, &priceM2)
+	if err != nil {
+		t.Fatalf("select ad: %s", err)
+	}
+	if streetID == nil {
+		t.Error("street id is not resolved")
+	}
+	if priceM2 != "900.01" {
+		t.Errorf("price m2 %s, want 900.01", priceM2)
+	}
+
+	history, err := client.PriceHistory(ctx, conn, id)
+	if err != nil {
+		t.Fatalf("price history: %s", err)
+	}
+	if len(history) != 1 || history[0].PriceOld.String() != "50000" || history[0].PriceNew.String() != "45000.5" {
+		t.Fatalf("unexpected price history %+v", history)
+	}
+
+	drops, err := client.PriceDrops(ctx, conn, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("price drops: %s", err)
+	}
+	if len(drops) != 1 {
+		t.Fatalf("got %d price drops, want 1", len(drops))
+	}
+
+	cnt, err := repo.Clean(ctx, time.Now().Add(time.Hour), profileKufar)
+	if err != nil {
+		t.Fatalf("clean: %s", err)
+	}
+	if cnt != 1 {
+		t.Errorf("cleaned %d ads, want 1", cnt)
+	}
+}
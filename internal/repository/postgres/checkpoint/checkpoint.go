@@ -0,0 +1,56 @@
+package checkpoint
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/model"
+)
+
+// Checkpoint keeps checkpoints of search in table checkpoint
+type Checkpoint struct {
+	conn *pgxpool.Pool
+}
+
+func NewCheckpoint(conn *pgxpool.Pool) *Checkpoint {
+	return &Checkpoint{
+		conn: conn,
+	}
+}
+
+func (c *Checkpoint) Load(ctx context.Context, profileID uint16) (*model.Checkpoint, error) {
+	cp := &model.Checkpoint{Profile: profileID}
+	err := c.conn.QueryRow(ctx, `SELECT num, next, started, incremental FROM checkpoint WHERE profile = $1`,
+		profileID).Scan(&cp.Num, &cp.Next, &cp.Started, &cp.Incremental)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "load checkpoint")
+	}
+
+	return cp, nil
+}
+
+func (c *Checkpoint) Save(ctx context.Context, cp *model.Checkpoint) error {
+	_, err := c.conn.Exec(ctx, `INSERT INTO checkpoint (profile, num, next, started, incremental, u_time)
+		VALUES ($1, $2, $3, $4, $5, now())
+		ON CONFLICT (profile) DO UPDATE SET num = $2, next = $3, started = $4, incremental = $5, u_time = now()`,
+		cp.Profile, cp.Num, []byte(cp.Next), cp.Started, cp.Incremental)
+	if err != nil {
+		return errors.Wrap(err, "save checkpoint")
+	}
+
+	return nil
+}
+
+func (c *Checkpoint) Reset(ctx context.Context, profileID uint16) error {
+	_, err := c.conn.Exec(ctx, `DELETE FROM checkpoint WHERE profile = $1`, profileID)
+	if err != nil {
+		return errors.Wrap(err, "reset checkpoint")
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package street
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	client "github.com/sku4/ad-parser/pkg/ad/postgres"
+	"github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+type Street struct {
+	client *client.Client
+}
+
+func NewStreet(conn *pgxpool.Pool) *Street {
+	return &Street{
+		client: client.NewClient(conn),
+	}
+}
+
+// Types returns street types by id from table street_type
+func (s *Street) Types(ctx context.Context) (map[uint8]*street.Type, error) {
+	types, err := s.client.StreetGetTypes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "types: street_type select")
+	}
+
+	return types, nil
+}
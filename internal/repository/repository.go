@@ -2,10 +2,25 @@ package repository
 
 import (
 	"context"
+	"io"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/repository/dedup"
+	jsonlAd "github.com/sku4/ad-parser/internal/repository/jsonl/ad"
+	memoryAd "github.com/sku4/ad-parser/internal/repository/memory/ad"
+	memoryCheckpoint "github.com/sku4/ad-parser/internal/repository/memory/checkpoint"
+	memoryStreet "github.com/sku4/ad-parser/internal/repository/memory/street"
+	postgresAd "github.com/sku4/ad-parser/internal/repository/postgres/ad"
+	postgresCheckpoint "github.com/sku4/ad-parser/internal/repository/postgres/checkpoint"
+	postgresStreet "github.com/sku4/ad-parser/internal/repository/postgres/street"
+	"github.com/sku4/ad-parser/internal/repository/street"
 	"github.com/sku4/ad-parser/internal/repository/tarantool/ad"
+	tarantoolCheckpoint "github.com/sku4/ad-parser/internal/repository/tarantool/checkpoint"
+	tarantoolStreet "github.com/sku4/ad-parser/internal/repository/tarantool/street"
 	"github.com/sku4/ad-parser/model"
+	streetModel "github.com/sku4/ad-parser/pkg/ad/street"
 	"github.com/tarantool/go-tarantool/v2/pool"
 )
 
@@ -13,15 +28,64 @@ import (
 
 type Ad interface {
 	Put(ctx context.Context, ad *model.Ad, profileID uint16) error
-	Clean(ctx context.Context, timeTo time.Time, profileID uint16) error
+	// PutBatch saves ads and returns error of each ad by its index, nil error means ad is saved
+	PutBatch(ctx context.Context, ads []*model.Ad, profileID uint16) []error
+	Clean(ctx context.Context, timeTo time.Time, profileID uint16) (uint64, error)
+	// Stored returns saved ads of profile with the given source ids, unknown source ids are skipped
+	Stored(ctx context.Context, sourceIDs []string, profileID uint16) ([]*model.Ad, error)
+	// MigrateSourceIDs sets source id of ads saved before it by url of ad, count of migrated ads is returned
+	MigrateSourceIDs(ctx context.Context, sourceID model.SourceIDFunc) (uint64, error)
+}
+
+// Checkpoint keeps search state of profiles, nil checkpoint is loaded when search is not interrupted
+type Checkpoint interface {
+	Load(ctx context.Context, profileID uint16) (*model.Checkpoint, error)
+	Save(ctx context.Context, checkpoint *model.Checkpoint) error
+	Reset(ctx context.Context, profileID uint16) error
+}
+
+// Street returns street types known by storage, addresses of ads are normalized by their aliases
+type Street interface {
+	Types(ctx context.Context) (map[uint8]*streetModel.Type, error)
 }
 
 type Repository struct {
 	Ad
+	Checkpoint
+	Street
+}
+
+func NewRepository(conn pool.Pooler, cfg *configs.Config) *Repository {
+	return &Repository{
+		Ad:         ad.NewAd(conn, street.NewCache(cfg.StreetCache), dedup.NewMatcher(cfg.Dedup)),
+		Checkpoint: tarantoolCheckpoint.NewCheckpoint(conn),
+		Street:     tarantoolStreet.NewStreet(conn),
+	}
+}
+
+// NewSinkRepository creates repository which writes ads to w as JSON Lines
+func NewSinkRepository(w io.Writer) *Repository {
+	return &Repository{
+		Ad:         jsonlAd.NewAd(w),
+		Checkpoint: memoryCheckpoint.NewCheckpoint(),
+		Street:     memoryStreet.NewStreet(),
+	}
+}
+
+// NewMemoryRepository creates repository which keeps ads in memory
+func NewMemoryRepository() *Repository {
+	return &Repository{
+		Ad:         memoryAd.NewAd(),
+		Checkpoint: memoryCheckpoint.NewCheckpoint(),
+		Street:     memoryStreet.NewStreet(),
+	}
 }
 
-func NewRepository(conn pool.Pooler) *Repository {
+// NewPostgresRepository creates repository which keeps ads in postgres
+func NewPostgresRepository(conn *pgxpool.Pool, cfg *configs.Config) *Repository {
 	return &Repository{
-		Ad: ad.NewAd(conn),
+		Ad:         postgresAd.NewAd(conn, street.NewCache(cfg.StreetCache), dedup.NewMatcher(cfg.Dedup)),
+		Checkpoint: postgresCheckpoint.NewCheckpoint(conn),
+		Street:     postgresStreet.NewStreet(conn),
 	}
 }
@@ -0,0 +1,108 @@
+package street
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/metrics"
+	"github.com/sku4/ad-parser/pkg/logger"
+)
+
+const (
+	defaultSize        = 10000
+	defaultTTL         = time.Hour * 24
+	defaultNegativeTTL = time.Hour
+)
+
+type entry struct {
+	id      *uint64
+	expires time.Time
+}
+
+// Cache keeps ids of streets by names to avoid street resolution calls on every ad,
+// names which are not resolved are cached too but for shorter time
+type Cache struct {
+	lru         *lru.Cache[string, entry]
+	ttl         time.Duration
+	negativeTTL time.Duration
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+}
+
+func NewCache(cfg configs.StreetCache) *Cache {
+	log := logger.Get()
+
+	if cfg.Size <= 0 {
+		cfg.Size = defaultSize
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.NegativeTTL <= 0 {
+		cfg.NegativeTTL = defaultNegativeTTL
+	}
+
+	cache, err := lru.New[string, entry](cfg.Size)
+	if err != nil {
+		log.Fatalf("error init street lru cache: %s", err)
+	}
+
+	return &Cache{
+		lru:         cache,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+	}
+}
+
+// Get returns id of street, ok is false when name is not cached,
+// nil id with true ok means that name was not resolved
+func (c *Cache) Get(name string) (id *uint64, ok bool) {
+	key := c.key(name)
+	e, ok := c.lru.Get(key)
+	if ok && time.Now().After(e.expires) {
+		c.lru.Remove(key)
+		ok = false
+	}
+
+	if !ok {
+		c.misses.Add(1)
+		metrics.StreetCacheMisses.Inc()
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	metrics.StreetCacheHits.Inc()
+	if e.id == nil {
+		return nil, true
+	}
+	streetID := *e.id
+
+	return &streetID, true
+}
+
+// Add caches resolved id of street
+func (c *Cache) Add(name string, id uint64) {
+	c.lru.Add(c.key(name), entry{
+		id:      &id,
+		expires: time.Now().Add(c.ttl),
+	})
+}
+
+// AddNotFound caches name which is not resolved to street
+func (c *Cache) AddNotFound(name string) {
+	c.lru.Add(c.key(name), entry{
+		expires: time.Now().Add(c.negativeTTL),
+	})
+}
+
+// Stats returns count of hits and misses of cache
+func (c *Cache) Stats() (hits, misses uint64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *Cache) key(name string) string {
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,54 @@
+package street
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sku4/ad-parser/configs"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache(configs.StreetCache{Size: 2, TTL: time.Hour, NegativeTTL: time.Hour})
+
+	if _, ok := c.Get("Победителей"); ok {
+		t.Fatal("empty cache returns street")
+	}
+
+	c.Add("Победителей", 7)
+	id, ok := c.Get(" Победителей ")
+	if !ok || id == nil || *id != 7 {
+		t.Fatalf("got %v %v, want 7 true", id, ok)
+	}
+
+	c.AddNotFound("Неизвестная")
+	id, ok = c.Get("Неизвестная")
+	if !ok || id != nil {
+		t.Fatalf("got %v %v, want nil true for not found street", id, ok)
+	}
+
+	// cache is bounded, least recently used name is evicted
+	c.Add("Притыцкого", 9)
+	if _, ok = c.Get("Победителей"); ok {
+		t.Error("least recently used street is not evicted")
+	}
+
+	hits, misses := c.Stats()
+	if hits != 2 || misses != 2 {
+		t.Errorf("got %d hits and %d misses, want 2 and 2", hits, misses)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := NewCache(configs.StreetCache{TTL: time.Hour, NegativeTTL: time.Millisecond})
+
+	c.Add("Победителей", 7)
+	c.AddNotFound("Неизвестная")
+	time.Sleep(time.Millisecond * 5)
+
+	if _, ok := c.Get("Победителей"); !ok {
+		t.Error("street is expired before ttl")
+	}
+	if _, ok := c.Get("Неизвестная"); ok {
+		t.Error("not found street is not expired after negative ttl")
+	}
+}
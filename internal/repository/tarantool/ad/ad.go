@@ -7,129 +7,113 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	dec "github.com/shopspring/decimal"
+	"github.com/sku4/ad-parser/internal/repository/dedup"
+	"github.com/sku4/ad-parser/internal/repository/street"
 	"github.com/sku4/ad-parser/model"
 	client "github.com/sku4/ad-parser/pkg/ad"
 	clientModel "github.com/sku4/ad-parser/pkg/ad/model"
 	"github.com/sku4/ad-parser/pkg/logger"
 	"github.com/tarantool/go-tarantool/v2"
 	"github.com/tarantool/go-tarantool/v2/datetime"
-	"github.com/tarantool/go-tarantool/v2/decimal"
 	"github.com/tarantool/go-tarantool/v2/pool"
 )
 
-const (
-	roundPlaces = 2
-)
+const batchLimitMigrate = 1000
 
 type Ad struct {
-	conn   pool.Pooler
-	client *client.Client
+	conn    pool.Pooler
+	client  *client.Client
+	streets *street.Cache
+	matcher *dedup.Matcher
 }
 
-func NewAd(conn pool.Pooler) *Ad {
+func NewAd(conn pool.Pooler, streets *street.Cache, matcher *dedup.Matcher) *Ad {
 	return &Ad{
-		conn:   conn,
-		client: client.NewClient(conn),
+		conn:    conn,
+		client:  client.NewClient(conn),
+		streets: streets,
+		matcher: matcher,
 	}
 }
 
+// Put saves one ad as batch of one ad
 func (ad *Ad) Put(ctx context.Context, modelAd *model.Ad, profileID uint16) error {
-	var adsTnt []clientModel.AdTnt
-	extIDSelect := tarantool.NewSelectRequest(clientModel.SpaceAd).
-		Index(clientModel.IndexExt).
-		Limit(1).
-		Iterator(tarantool.IterEq).
-		Key(tarantool.UintKey{I: uint(modelAd.ExtID)})
-	err := ad.conn.Do(extIDSelect, pool.PreferRW).GetTyped(&adsTnt)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("put: ext_id select %d", modelAd.ExtID))
+	return ad.PutBatch(ctx, []*model.Ad{modelAd}, profileID)[0]
+}
+
+func (ad *Ad) PutBatch(ctx context.Context, ads []*model.Ad, profileID uint16) []error {
+	errs := make([]error, len(ads))
+	if len(ads) == 0 {
+		return errs
 	}
 
 	updated, err := datetime.NewDatetime(time.Now().UTC())
 	if err != nil {
-		return errors.Wrap(err, "put: time convert to datetime")
+		return fillErrors(errs, errors.Wrap(err, "put batch: time convert to datetime"))
 	}
 
-	// get street id
-	if modelAd.Street != nil && *modelAd.Street != "" {
-		streetGetIDTnt, errStreet := ad.client.StreetGetID(ctx, *modelAd.Street)
-		if errStreet != nil {
-			return errors.Wrap(errStreet, "put: street.get_id")
-		}
-		if streetGetIDTnt.Status == http.StatusOK {
-			modelAd.StreetID = &streetGetIDTnt.ID
-		}
+	streetIDs, err := ad.streetIDs(ctx, ads)
+	if err != nil {
+		return fillErrors(errs, errors.Wrap(err, "put batch: street.get_ids"))
 	}
 
-	modelAd.Updated = updated
-	modelAd.Profile = profileID
-
-	if modelAd.Price != nil && modelAd.M2Main != nil && *modelAd.M2Main > 0 {
-		m2Main := dec.NewFromFloat(*modelAd.M2Main)
-		modelAd.PriceM2 = decimal.NewDecimal(modelAd.Price.Div(m2Main).Round(roundPlaces))
-	}
-
-	if len(adsTnt) > 0 {
-		// if ad exists - update u_time
-		operations := tarantool.NewOperations().
-			Assign(clientModel.SpaceAdFieldUTime, updated).
-			Assign(clientModel.SpaceAdFieldLocLat, modelAd.LocLat).
-			Assign(clientModel.SpaceAdFieldLocLong, modelAd.LocLong).
-			Assign(clientModel.SpaceAdFieldHouse, modelAd.House).
-			Assign(clientModel.SpaceAdFieldPrice, modelAd.Price).
-			Assign(clientModel.SpaceAdFieldPriceM2, modelAd.PriceM2).
-			Assign(clientModel.SpaceAdFieldRooms, modelAd.Rooms).
-			Assign(clientModel.SpaceAdFieldFloor, modelAd.Floor).
-			Assign(clientModel.SpaceAdFieldFloors, modelAd.Floors).
-			Assign(clientModel.SpaceAdFieldYear, modelAd.Year).
-			Assign(clientModel.SpaceAdFieldPhotos, modelAd.Photos).
-			Assign(clientModel.SpaceAdFieldM2Main, modelAd.M2Main).
-			Assign(clientModel.SpaceAdFieldM2Living, modelAd.M2Living).
-			Assign(clientModel.SpaceAdFieldM2Kitchen, modelAd.M2Kitchen).
-			Assign(clientModel.SpaceAdFieldBathroom, modelAd.Bathroom)
-		if modelAd.StreetID == nil {
-			operations.Assign(clientModel.SpaceAdFieldStreetID, nil)
-		} else {
-			operations.Assign(clientModel.SpaceAdFieldStreetID, uint(*modelAd.StreetID))
-		}
-		timeUpdate := tarantool.NewUpdateRequest(clientModel.SpaceAd).
-			Index(clientModel.IndexExt).
-			Key(tarantool.UintKey{I: uint(modelAd.ExtID)}).
-			Operations(operations)
-		_, errUpd := ad.conn.Do(timeUpdate, pool.RW).Get()
-		if errUpd != nil {
-			return errors.Wrap(errUpd, "put: update")
+	for _, modelAd := range ads {
+		if modelAd.Street != nil {
+			modelAd.StreetID = streetIDs[*modelAd.Street]
 		}
-
-		return nil
+		modelAd.Updated = updated
+		modelAd.Profile = profileID
+		modelAd.CalcPriceM2()
 	}
 
-	// put ad
-	adTuple, err := modelAd.ConvertToTuple()
+	// find ads of the same apartment from other profiles
+	matched, err := ad.matcher.Group(ctx, ads, ad.candidates)
 	if err != nil {
-		return errors.Wrap(err, "put")
+		return fillErrors(errs, errors.Wrap(err, "put batch: ad.candidates"))
+	}
+
+	tuples := make([]map[string]any, 0, len(ads))
+	detailed := make([]bool, 0, len(ads))
+	for _, modelAd := range ads {
+		adTuple, errTuple := modelAd.ConvertToTuple()
+		if errTuple != nil {
+			return fillErrors(errs, errors.Wrap(errTuple, "put batch"))
+		}
+		tuples = append(tuples, adTuple)
+		detailed = append(detailed, modelAd.Detailed)
 	}
 
-	callPut := tarantool.NewCallRequest("box.space.ad:put").Args([]interface{}{adTuple})
-	_, err = ad.conn.Do(callPut, pool.RW).Get()
+	results, err := ad.client.AdPutBatch(ctx, tuples, detailed, profileID)
 	if err != nil {
-		return errors.Wrap(err, "put: call")
+		return fillErrors(errs, errors.Wrap(err, "put batch: call"))
+	}
+
+	newAds := false
+	for i, result := range results {
+		if result.Status != http.StatusOK {
+			errs[i] = errors.Wrap(clientModel.ErrInternalServerError,
+				fmt.Sprintf("put batch: source_id %s: %s", result.SourceID, result.Code))
+			continue
+		}
+		newAds = newAds || (result.New && !matched[i])
 	}
 
-	// send broadcast event as put new ad
-	ad.conn.Do(tarantool.NewBroadcastRequest(clientModel.EventNewAd).Value(true), pool.RO)
+	// send one broadcast event for all new ads of batch,
+	// ads of known apartments are not announced again
+	if newAds {
+		ad.conn.Do(tarantool.NewBroadcastRequest(clientModel.EventNewAd).Value(true), pool.RO)
+	}
 
-	return nil
+	return errs
 }
 
-func (ad *Ad) Clean(ctx context.Context, timeTo time.Time, profileID uint16) error {
+func (ad *Ad) Clean(ctx context.Context, timeTo time.Time, profileID uint16) (uint64, error) {
 	log := logger.Get()
 
 	// clean ads
 	cntClean, err := ad.client.AdsClean(ctx, timeTo, profileID)
 	if err != nil {
-		return errors.Wrap(err, "clean: call")
+		return 0, errors.Wrap(err, "clean: call")
 	}
 
 	profileCode := ad.client.ProfileGetByID(ctx, profileID)
@@ -137,5 +121,130 @@ func (ad *Ad) Clean(ctx context.Context, timeTo time.Time, profileID uint16) err
 	log.Infof("Clean %d tuples before time %s for '%s' profile",
 		cntClean, timeTo.Format(time.DateTime), profileCode)
 
-	return nil
+	hits, misses := ad.streets.Stats()
+	log.Infof("Street cache: %d hits, %d misses", hits, misses)
+
+	return cntClean, nil
+}
+
+// streetIDs returns ids of streets of ads, streets missed in cache are resolved in one call
+func (ad *Ad) streetIDs(ctx context.Context, ads []*model.Ad) (map[string]*uint64, error) {
+	streetIDs := make(map[string]*uint64)
+	names := make([]string, 0)
+	for _, modelAd := range ads {
+		if modelAd.Street == nil || *modelAd.Street == "" {
+			continue
+		}
+		name := *modelAd.Street
+		if _, ok := streetIDs[name]; ok {
+			continue
+		}
+		streetID, ok := ad.streets.Get(name)
+		if !ok {
+			names = append(names, name)
+		}
+		streetIDs[name] = streetID
+	}
+
+	if len(names) == 0 {
+		return streetIDs, nil
+	}
+
+	resolved, err := ad.client.StreetGetIDs(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		streetID, ok := resolved[name]
+		if !ok {
+			ad.streets.AddNotFound(name)
+			continue
+		}
+		ad.streets.Add(name, streetID)
+		streetIDs[name] = &streetID
+	}
+
+	return streetIDs, nil
+}
+
+// Stored returns saved ads of profile with the given source ids, only update time and prices are set
+func (ad *Ad) Stored(ctx context.Context, sourceIDs []string, profileID uint16) ([]*model.Ad, error) {
+	adsTnt, err := ad.client.AdsBySource(ctx, profileID, sourceIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "stored: ad.by_source")
+	}
+
+	ads := make([]*model.Ad, 0, len(adsTnt))
+	for _, adTnt := range adsTnt {
+		ads = append(ads, &model.Ad{
+			SourceID:   adTnt.SourceID,
+			Updated:    adTnt.Updated,
+			Price:      adTnt.Price,
+			PriceMonth: adTnt.PriceMonth,
+			Profile:    adTnt.Profile,
+		})
+	}
+
+	return ads, nil
+}
+
+// MigrateSourceIDs sets source id of ads saved before it by url of ad and rehashes group id
+// of group keyed by ext id of ad, ads with url unknown by profile are skipped
+func (ad *Ad) MigrateSourceIDs(ctx context.Context, sourceID model.SourceIDFunc) (uint64, error) {
+	log := logger.Get()
+
+	var after, cnt, skipped uint64
+	for {
+		batch, err := ad.client.AdsWithoutSourceID(ctx, after, batchLimitMigrate)
+		if err != nil {
+			return cnt, errors.Wrap(err, "migrate source ids: ad.without_source_id")
+		}
+		if len(batch) == 0 {
+			break
+		}
+		after = batch[len(batch)-1].ID
+
+		ads := make([]*clientModel.AdSourceTnt, 0, len(batch))
+		for _, a := range batch {
+			id, ok := sourceID(a.Profile, a.URL)
+			if !ok {
+				skipped++
+				continue
+			}
+			a.SourceID = id
+			a.GroupID = model.SourceKey{Profile: a.Profile, SourceID: id}.Hash()
+			ads = append(ads, a)
+		}
+		if len(ads) > 0 {
+			if err = ad.client.AdSetSourceIDs(ctx, ads); err != nil {
+				return cnt, errors.Wrap(err, "migrate source ids: ad.set_source_ids")
+			}
+		}
+		cnt += uint64(len(ads))
+
+		if len(batch) < batchLimitMigrate {
+			break
+		}
+	}
+
+	log.Infof("Source id is set to %d ads, %d ads with unknown url are skipped", cnt, skipped)
+
+	return cnt, nil
+}
+
+func (ad *Ad) candidates(ctx context.Context, keys []*clientModel.CandidateKey) ([][]*model.Ad, error) {
+	candidates, err := ad.client.AdCandidates(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return dedup.FromTnt(candidates), nil
+}
+
+func fillErrors(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
 }
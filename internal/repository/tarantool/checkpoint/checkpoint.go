@@ -0,0 +1,83 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/model"
+	"github.com/tarantool/go-tarantool/v2"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/pool"
+)
+
+const (
+	spaceCheckpoint = "checkpoint"
+)
+
+// Checkpoint keeps checkpoints of search in space checkpoint
+// with format {profile, num, next, started, incremental}
+type Checkpoint struct {
+	conn pool.Pooler
+}
+
+type checkpointTnt struct {
+	Profile     uint16
+	Num         int
+	Next        string
+	Started     datetime.Datetime
+	Incremental bool
+}
+
+func NewCheckpoint(conn pool.Pooler) *Checkpoint {
+	return &Checkpoint{
+		conn: conn,
+	}
+}
+
+func (c *Checkpoint) Load(_ context.Context, profileID uint16) (*model.Checkpoint, error) {
+	var cpsTnt []checkpointTnt
+	cpSelect := tarantool.NewSelectRequest(spaceCheckpoint).
+		Limit(1).
+		Iterator(tarantool.IterEq).
+		Key([]interface{}{profileID})
+	if err := c.conn.Do(cpSelect, pool.PreferRW).GetTyped(&cpsTnt); err != nil {
+		return nil, errors.Wrap(err, "load checkpoint")
+	}
+	if len(cpsTnt) == 0 {
+		return nil, nil
+	}
+
+	return &model.Checkpoint{
+		Profile:     cpsTnt[0].Profile,
+		Num:         cpsTnt[0].Num,
+		Next:        json.RawMessage(cpsTnt[0].Next),
+		Started:     cpsTnt[0].Started.ToTime(),
+		Incremental: cpsTnt[0].Incremental,
+	}, nil
+}
+
+func (c *Checkpoint) Save(_ context.Context, cp *model.Checkpoint) error {
+	started, err := datetime.NewDatetime(cp.Started.UTC())
+	if err != nil {
+		return errors.Wrap(err, "save checkpoint: time convert to datetime")
+	}
+
+	cpReplace := tarantool.NewReplaceRequest(spaceCheckpoint).
+		Tuple([]interface{}{cp.Profile, cp.Num, string(cp.Next), started, cp.Incremental})
+	if _, err = c.conn.Do(cpReplace, pool.RW).Get(); err != nil {
+		return errors.Wrap(err, "save checkpoint")
+	}
+
+	return nil
+}
+
+func (c *Checkpoint) Reset(_ context.Context, profileID uint16) error {
+	cpDelete := tarantool.NewDeleteRequest(spaceCheckpoint).
+		Key([]interface{}{profileID})
+	if _, err := c.conn.Do(cpDelete, pool.RW).Get(); err != nil {
+		return errors.Wrap(err, "reset checkpoint")
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package street
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	client "github.com/sku4/ad-parser/pkg/ad"
+	"github.com/sku4/ad-parser/pkg/ad/street"
+	"github.com/tarantool/go-tarantool/v2/pool"
+)
+
+type Street struct {
+	client *client.Client
+}
+
+func NewStreet(conn pool.Pooler) *Street {
+	return &Street{
+		client: client.NewClient(conn),
+	}
+}
+
+// Types returns street types by id from procedure street.get_types
+func (s *Street) Types(ctx context.Context) (map[uint8]*street.Type, error) {
+	types, err := s.client.StreetGetTypes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "types: street.get_types")
+	}
+
+	return types, nil
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	readHeaderTimeout = time.Second * 10
+)
+
+type Server struct {
+	httpServer *http.Server
+}
+
+func NewServer(port int, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+	}
+}
+
+func (s *Server) Run() error {
+	return s.httpServer.ListenAndServe()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,275 @@
+package address
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+var (
+	// dotGlued matches abbreviation glued to next word like "ул.Притыцкого"
+	dotGlued = regexp.MustCompile(`(\p{L})\.(\p{L}|\d)`)
+	// wordRe matches word of street name, part without words like "12-1" is not street
+	wordRe = regexp.MustCompile(`\p{L}{2,}`)
+	// wordSep splits part to words for matching of skip words
+	wordSep = regexp.MustCompile(`[^\p{L}\d-]+`)
+)
+
+// Address is normalized address of ad
+type Address struct {
+	Street   string
+	Type     *street.Type
+	House    string
+	Korpus   string
+	Building string
+}
+
+// StreetName returns street with short type word in place defined by type
+func (a Address) StreetName() string {
+	if a.Street == "" || a.Type == nil || a.Type.Short == "" {
+		return a.Street
+	}
+	if a.Type.InStart {
+		return a.Type.Short + " " + a.Street
+	}
+
+	return a.Street + " " + a.Type.Short
+}
+
+// HouseNumber returns house with korpus and building like "12А", "12к2", "12к2с1"
+func (a Address) HouseNumber() string {
+	house := a.House
+	if a.Korpus != "" {
+		house += "к" + a.Korpus
+	}
+	if a.Building != "" {
+		house += "с" + a.Building
+	}
+
+	return house
+}
+
+// Ptrs returns street name and house number as model fields, empty values are nil
+func (a Address) Ptrs() (streetName, houseNumber *string) {
+	if s := a.StreetName(); s != "" {
+		streetName = &s
+	}
+	if h := a.HouseNumber(); h != "" {
+		houseNumber = &h
+	}
+
+	return streetName, houseNumber
+}
+
+type alias struct {
+	word string
+	typ  *street.Type
+}
+
+// Normalizer brings addresses of all profiles to one form so same street
+// is resolved to same id regardless of source
+type Normalizer struct {
+	aliases []alias
+}
+
+func NewNormalizer(types []*street.Type) *Normalizer {
+	aliases := make([]alias, 0)
+	for _, t := range types {
+		for _, a := range t.Any {
+			aliases = append(aliases, alias{
+				word: strings.ToLower(a),
+				typ:  t,
+			})
+		}
+	}
+	// longer aliases first so "просп." is not matched as "пр."
+	sort.SliceStable(aliases, func(i, j int) bool {
+		return len([]rune(aliases[i].word)) > len([]rune(aliases[j].word))
+	})
+
+	return &Normalizer{
+		aliases: aliases,
+	}
+}
+
+type normalizerKey struct{}
+
+// Set returns context with normalizer built by street types of storage
+func Set(ctx context.Context, n *Normalizer) context.Context {
+	return context.WithValue(ctx, normalizerKey{}, n)
+}
+
+// Get returns normalizer from context, normalizer without street types is returned when it is not set
+func Get(ctx context.Context) *Normalizer {
+	if n, ok := ctx.Value(normalizerKey{}).(*Normalizer); ok {
+		return n
+	}
+
+	return NewNormalizer(nil)
+}
+
+// Split parses full address like "Минск, ул. Притыцкого, 12к2" by normalizer of context,
+// parts which are one of skip words (cities, regions) are dropped
+func Split(ctx context.Context, address string, skip ...string) Address {
+	return Get(ctx).Split(address, skip...)
+}
+
+// Normalize parses street and house which are given by source separately by normalizer of context
+func Normalize(ctx context.Context, streetName, house string) Address {
+	return Get(ctx).Normalize(streetName, house)
+}
+
+func (n *Normalizer) Split(address string, skip ...string) Address {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(clean(address), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || n.skipped(part, skip) {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return Address{}
+	}
+
+	addr := Address{}
+	streetParts, ok := splitHouseParts(parts, &addr)
+	if !ok {
+		// house is written in street part after space like "ул. Одинцова 36"
+		last := streetParts[len(streetParts)-1]
+		if s, found := splitHouseWords(last, &addr); found {
+			streetParts[len(streetParts)-1] = s
+		}
+	}
+
+	// address goes from common to particular, so street is last part before house
+	last := streetParts[len(streetParts)-1]
+	if !wordRe.MatchString(last) {
+		// address has no street like "Ждановичи, 12-1"
+		if !ok {
+			setHouse(&addr, last)
+		}
+		return addr
+	}
+	addr.Street, addr.Type = n.Street(last)
+
+	return addr
+}
+
+func (n *Normalizer) Normalize(streetName, house string) Address {
+	addr := Address{}
+	addr.Street, addr.Type = n.Street(clean(streetName))
+
+	house = strings.TrimSpace(clean(house))
+	if house == "" {
+		return addr
+	}
+	setHouse(&addr, house)
+
+	return addr
+}
+
+// Street returns street name without type words and type found by aliases,
+// latin words and latin letters in cyrillic words are converted to cyrillic
+func (n *Normalizer) Street(name string) (string, *street.Type) {
+	words := strings.Fields(clean(name))
+	for i, w := range words {
+		words[i] = ToCyrillic(w)
+	}
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	if len(words) > 1 {
+		if t := n.typeOf(words[0]); t != nil {
+			return strings.Join(words[1:], " "), t
+		}
+		if t := n.typeOf(words[len(words)-1]); t != nil {
+			return strings.Join(words[:len(words)-1], " "), t
+		}
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+func (n *Normalizer) typeOf(word string) *street.Type {
+	word = strings.ToLower(word)
+	for _, a := range n.aliases {
+		if word == a.word {
+			return a.typ
+		}
+	}
+
+	return nil
+}
+
+// splitHouseParts cuts longest tail of comma parts which is house, street parts are returned
+func splitHouseParts(parts []string, addr *Address) ([]string, bool) {
+	for k := 1; k < len(parts); k++ {
+		if h, ok := ParseHouse(strings.Join(parts[k:], " ")); ok {
+			addr.House, addr.Korpus, addr.Building = h.House, h.Korpus, h.Building
+			return parts[:k], true
+		}
+	}
+
+	return parts, false
+}
+
+// splitHouseWords cuts longest tail of words which is house, street is returned
+func splitHouseWords(part string, addr *Address) (string, bool) {
+	words := strings.Fields(part)
+	for k := 1; k < len(words); k++ {
+		if h, ok := ParseHouse(strings.Join(words[k:], " ")); ok {
+			addr.House, addr.Korpus, addr.Building = h.House, h.Korpus, h.Building
+			return strings.Join(words[:k], " "), true
+		}
+	}
+
+	return part, false
+}
+
+func clean(s string) string {
+	s = dotGlued.ReplaceAllString(s, "$1. $2")
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// setHouse sets house, korpus and building of address, house which is not parsed is set as is
+func setHouse(addr *Address, house string) {
+	if h, ok := ParseHouse(house); ok {
+		addr.House, addr.Korpus, addr.Building = h.House, h.Korpus, h.Building
+	} else {
+		addr.House = house
+	}
+}
+
+// skipped reports whether part is one of skip words or has it as whole word like "г. Минск",
+// part with street type like "ул. Минская" is never skipped
+func (n *Normalizer) skipped(part string, words []string) bool {
+	for _, w := range strings.Fields(part) {
+		if n.typeOf(w) != nil {
+			return false
+		}
+	}
+
+	partWords := wordSep.Split(strings.ToLower(part), -1)
+	for _, skip := range words {
+		skip = strings.ToLower(strings.TrimSpace(skip))
+		if skip == "" {
+			continue
+		}
+		if skip == strings.ToLower(part) {
+			return true
+		}
+		for _, w := range partWords {
+			if w == skip {
+				return true
+			}
+		}
+	}
+
+	return false
+}
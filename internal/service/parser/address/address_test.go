@@ -0,0 +1,178 @@
+package address
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sku4/ad-parser/internal/service/parser/address/addresstest"
+	"github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+func TestSplit(t *testing.T) {
+	n := NewNormalizer(addresstest.Types)
+	cities := []string{"Минск", "Беларусь", "Minsk"}
+	tests := []struct {
+		address string
+		street  string
+		house   string
+	}{
+		{"Минск, улица Притыцкого, 10", "ул. Притыцкого", "10"},
+		{"Минск, ул. Одинцова 36", "ул. Одинцова", "36"},
+		{"улица Сурганова, 57Б", "ул. Сурганова", "57Б"},
+		{"пр. Дзержинского, 104", "пр-т Дзержинского", "104"},
+		{"проспект Независимости", "пр-т Независимости", ""},
+		{"Ждановичи, Центральная, 4", "Центральная", "4"},
+		{"ул.Притыцкого, д.12", "ул. Притыцкого", "12"},
+		{"Притыцкого ул., 12 корп. 2", "ул. Притыцкого", "12к2"},
+		{"Притыцкого ул., 12, корп. 2", "ул. Притыцкого", "12к2"},
+		{"пер. Калинина 5/1 стр. 3", "пер. Калинина", "5к1с3"},
+		{"Минск, ул. 50 лет Победы, 12а", "ул. 50 лет Победы", "12А"},
+		{"Minsk, ul. Pritytskogo, 10", "ул. Притыцкого", "10"},
+		{"Минск, ул. Притыцкогo 10", "ул. Притыцкого", "10"},
+		{"Минск, ул. Минская, 5", "ул. Минская", "5"},
+		{"г. Минск, Минская, 5", "Минская", "5"},
+		{"Беларусь, Минск, Притыцкого ул., 12", "ул. Притыцкого", "12"},
+		{"Ждановичи, 12-1", "", "12-1"},
+		{"Минск, 5", "", "5"},
+		{"Минск", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			addr := n.Split(tt.address, cities...)
+			if addr.StreetName() != tt.street || addr.HouseNumber() != tt.house {
+				t.Errorf("got %q, %q, want %q, %q",
+					addr.StreetName(), addr.HouseNumber(), tt.street, tt.house)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	// street types are not known without normalizer of storage
+	addr := Split(context.Background(), "Минск, улица Притыцкого, 10", "Минск")
+	if addr.StreetName() != "улица Притыцкого" || addr.HouseNumber() != "10" {
+		t.Errorf("got %q, %q without normalizer", addr.StreetName(), addr.HouseNumber())
+	}
+
+	ctx := Set(context.Background(), NewNormalizer(addresstest.Types))
+	addr = Split(ctx, "Минск, улица Притыцкого, 10", "Минск")
+	if addr.StreetName() != "ул. Притыцкого" || addr.HouseNumber() != "10" {
+		t.Errorf("got %q, %q with normalizer", addr.StreetName(), addr.HouseNumber())
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	n := NewNormalizer(addresstest.Types)
+	tests := []struct {
+		street    string
+		house     string
+		wantStr   string
+		wantHouse string
+	}{
+		{"Притыцкого", "10", "Притыцкого", "10"},
+		{"улица Притыцкого", "57/Б", "ул. Притыцкого", "57Б"},
+		{"Победителей пр-т", "5/2", "пр-т Победителей", "5к2"},
+		{"Кальварийская", "уч. 3", "Кальварийская", "уч. 3"},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.street+" "+tt.house, func(t *testing.T) {
+			addr := n.Normalize(tt.street, tt.house)
+			if addr.StreetName() != tt.wantStr || addr.HouseNumber() != tt.wantHouse {
+				t.Errorf("got %q, %q, want %q, %q",
+					addr.StreetName(), addr.HouseNumber(), tt.wantStr, tt.wantHouse)
+			}
+		})
+	}
+}
+
+func TestStreetType(t *testing.T) {
+	n := NewNormalizer([]*street.Type{
+		{ID: 1, Short: "ул.", Any: []string{"улица", "ул."}, InStart: true},
+		{ID: 9, Short: "шоссе", Any: []string{"шоссе", "ш."}, InStart: false},
+	})
+	tests := []struct {
+		name   string
+		street string
+		typeID uint8
+		full   string
+	}{
+		{"УЛИЦА Притыцкого", "Притыцкого", 1, "ул. Притыцкого"},
+		{"ш. Логойский", "Логойский", 9, "Логойский шоссе"},
+		{"Логойский шоссе", "Логойский", 9, "Логойский шоссе"},
+		{"Притыцкого", "Притыцкого", 0, "Притыцкого"},
+		{"улица", "улица", 0, "улица"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, typ := n.Street(tt.name)
+			var typeID uint8
+			if typ != nil {
+				typeID = typ.ID
+			}
+			full := Address{Street: name, Type: typ}.StreetName()
+			if name != tt.street || typeID != tt.typeID || full != tt.full {
+				t.Errorf("got %q type %d %q, want %q type %d %q",
+					name, typeID, full, tt.street, tt.typeID, tt.full)
+			}
+		})
+	}
+}
+
+func TestParseHouse(t *testing.T) {
+	tests := []struct {
+		s    string
+		want House
+		ok   bool
+	}{
+		{"12", House{House: "12"}, true},
+		{"д. 12а", House{House: "12А"}, true},
+		{"дом 7", House{House: "7"}, true},
+		{"12к2", House{House: "12", Korpus: "2"}, true},
+		{"12 корпус 2а", House{House: "12", Korpus: "2А"}, true},
+		{"12/3", House{House: "12", Korpus: "3"}, true},
+		{"12с1", House{House: "12", Building: "1"}, true},
+		{"12к2 строение 1", House{House: "12", Korpus: "2", Building: "1"}, true},
+		{"Притыцкого", House{}, false},
+		{"50 лет Победы", House{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := ParseHouse(tt.s)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("got %+v %v, want %+v %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTranslit(t *testing.T) {
+	tests := []struct {
+		latin    string
+		cyrillic string
+	}{
+		{"Pritytskogo", "Притыцкого"},
+		{"Pobediteley", "Победителей"},
+		{"Shchorsa", "Щорса"},
+		{"Zhukova", "Жукова"},
+		{"Yakuba", "Якуба"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.latin, func(t *testing.T) {
+			if got := ToCyrillic(tt.latin); got != tt.cyrillic {
+				t.Errorf("to cyrillic %q, want %q", got, tt.cyrillic)
+			}
+			if got := ToCyrillic(tt.cyrillic); got != tt.cyrillic {
+				t.Errorf("cyrillic word is changed to %q", got)
+			}
+		})
+	}
+
+	if got := ToCyrillic("Cуpганова"); got != "Сурганова" {
+		t.Errorf("latin lookalikes are not replaced: %q", got)
+	}
+	if got := ToLatin("Щорса, Жукова"); got != "Shchorsa, Zhukova" {
+		t.Errorf("to latin %q", got)
+	}
+}
@@ -0,0 +1,17 @@
+// Package addresstest provides street types seeded to storages for tests of profiles,
+// the service reads street types from storage on start
+package addresstest
+
+import "github.com/sku4/ad-parser/pkg/ad/street"
+
+// Types are street types seeded to storages
+var Types = []*street.Type{
+	{ID: 1, Short: "ул.", Any: []string{"улица", "ул.", "ул"}, InStart: true},
+	{ID: 2, Short: "пр-т", Any: []string{"проспект", "пр-т", "просп.", "пр."}, InStart: true},
+	{ID: 3, Short: "пер.", Any: []string{"переулок", "пер.", "пер"}, InStart: true},
+	{ID: 4, Short: "б-р", Any: []string{"бульвар", "б-р"}, InStart: true},
+	{ID: 5, Short: "пр-д", Any: []string{"проезд", "пр-д"}, InStart: true},
+	{ID: 6, Short: "тр.", Any: []string{"тракт", "тр."}, InStart: true},
+	{ID: 7, Short: "пл.", Any: []string{"площадь", "пл."}, InStart: true},
+	{ID: 8, Short: "туп.", Any: []string{"тупик", "туп."}, InStart: true},
+}
@@ -0,0 +1,36 @@
+package address
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// houseRe matches house with optional letter, korpus and building like
+	// "д. 12", "12А", "12/2", "12 корп. 2", "12к2 стр. 1"
+	houseRe = regexp.MustCompile(`^(?:(?:д|дом)\.?\s*)?(\d+)(?:\s*/?\s*([а-яё]))?` +
+		`(?:\s*(?:/|к|корп|корпус)\.?\s*(\d+[а-яё]?))?` +
+		`(?:\s*(?:с|стр|строение)\.?\s*(\d+))?$`)
+)
+
+// House is parsed house number
+type House struct {
+	House    string
+	Korpus   string
+	Building string
+}
+
+// ParseHouse parses house number, letters are upper case in result
+func ParseHouse(s string) (House, bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	m := houseRe.FindStringSubmatch(s)
+	if m == nil {
+		return House{}, false
+	}
+
+	return House{
+		House:    m[1] + strings.ToUpper(m[2]),
+		Korpus:   strings.ToUpper(m[3]),
+		Building: m[4],
+	}, true
+}
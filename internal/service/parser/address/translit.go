@@ -0,0 +1,128 @@
+package address
+
+import (
+	"strings"
+	"unicode"
+)
+
+var (
+	// homoglyphs are latin letters which look like cyrillic ones,
+	// they are mistyped in cyrillic words by users
+	homoglyphs = map[rune]rune{
+		'a': 'а', 'c': 'с', 'e': 'е', 'o': 'о', 'p': 'р', 'x': 'х', 'y': 'у',
+		'A': 'А', 'B': 'В', 'C': 'С', 'E': 'Е', 'H': 'Н', 'K': 'К', 'M': 'М',
+		'O': 'О', 'P': 'Р', 'T': 'Т', 'X': 'Х',
+	}
+
+	// latinToCyrillic is ordered by length so digraphs are matched first
+	latinToCyrillic = [][2]string{
+		{"shch", "щ"}, {"sch", "щ"},
+		{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+		{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ye", "е"}, {"iy", "ий"}, {"yy", "ый"},
+		{"a", "а"}, {"b", "б"}, {"v", "в"}, {"g", "г"}, {"d", "д"}, {"e", "е"},
+		{"z", "з"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"},
+		{"n", "н"}, {"o", "о"}, {"p", "п"}, {"r", "р"}, {"s", "с"}, {"t", "т"},
+		{"u", "у"}, {"f", "ф"}, {"h", "х"}, {"c", "ц"}, {"w", "в"}, {"x", "кс"},
+		{"y", "ы"}, {"'", "ь"},
+	}
+
+	cyrillicToLatin = map[rune]string{
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+		'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+		'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+		'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+		'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+		'і': "i", 'ў': "w",
+	}
+)
+
+// ToCyrillic converts word to cyrillic: latin lookalikes in cyrillic word are replaced,
+// word written in latin only is transliterated, other words are returned as is
+func ToCyrillic(word string) string {
+	hasCyrillic, hasLatin := false, false
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			hasCyrillic = true
+		case r <= unicode.MaxASCII && unicode.IsLetter(r):
+			hasLatin = true
+		}
+	}
+
+	switch {
+	case hasCyrillic && hasLatin:
+		return strings.Map(func(r rune) rune {
+			if c, ok := homoglyphs[r]; ok {
+				return c
+			}
+			return r
+		}, word)
+	case hasLatin:
+		return latinWord(word)
+	default:
+		return word
+	}
+}
+
+// ToLatin transliterates cyrillic text to latin, case of first letter of replacement is kept
+func ToLatin(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		l, ok := cyrillicToLatin[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) {
+			l = upperFirst(l)
+		}
+		b.WriteString(l)
+	}
+
+	return b.String()
+}
+
+func latinWord(word string) string {
+	lower := strings.ToLower(word)
+	if len(lower) != len(word) {
+		return word
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(lower); {
+		replaced := false
+		for _, lc := range latinToCyrillic {
+			if !strings.HasPrefix(lower[i:], lc[0]) {
+				continue
+			}
+			c := lc[1]
+			// "y" after vowel is "й" like in "Pobediteley"
+			if lc[0] == "y" && i > 0 && strings.IndexByte("aeiou", lower[i-1]) >= 0 {
+				c = "й"
+			}
+			if word[i] >= 'A' && word[i] <= 'Z' {
+				c = upperFirst(c)
+			}
+			b.WriteString(c)
+			i += len(lc[0])
+			replaced = true
+			break
+		}
+		if !replaced {
+			b.WriteByte(word[i])
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToUpper(r[0])
+
+	return string(r)
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/repository"
+	"github.com/sku4/ad-parser/model"
+)
+
+// searched is page of search which ads are not saved yet, checkpoint is search state
+// after the page, it is nil after the last page
+type searched struct {
+	checkpoint *model.Checkpoint
+	left       int
+}
+
+// checkpoints advances checkpoint of search only when ads of all searched pages before it are saved,
+// so ads found but not saved by interrupted search are found again by resumed search
+type checkpoints struct {
+	mu        sync.Mutex
+	repos     *repository.Repository
+	profileID uint16
+	pages     []*searched
+	failed    bool
+}
+
+func newCheckpoints(repos *repository.Repository, profileID uint16) *checkpoints {
+	return &checkpoints{
+		repos:     repos,
+		profileID: profileID,
+	}
+}
+
+// add adds searched page with count of its ads, checkpoint is nil for the last page
+func (c *checkpoints) add(ctx context.Context, cp *model.Checkpoint, ads int) (*searched, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	page := &searched{checkpoint: cp, left: ads}
+	c.pages = append(c.pages, page)
+
+	return page, c.advance(ctx)
+}
+
+// saved marks ad of page as saved, checkpoint is not advanced any more when ad is not saved
+func (c *checkpoints) saved(ctx context.Context, page *searched, ok bool) error {
+	if page == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	page.left--
+	if !ok {
+		c.failed = true
+	}
+
+	return c.advance(ctx)
+}
+
+// advance saves checkpoint of the last page which ads are saved with ads of all pages before it,
+// checkpoint is reset when ads of the last page of search are saved
+func (c *checkpoints) advance(ctx context.Context) error {
+	if c.failed {
+		return nil
+	}
+
+	var last *searched
+	for len(c.pages) > 0 && c.pages[0].left <= 0 {
+		last, c.pages = c.pages[0], c.pages[1:]
+	}
+	if last == nil {
+		return nil
+	}
+
+	if last.checkpoint == nil {
+		return errors.Wrap(c.repos.Checkpoint.Reset(ctx, c.profileID), "reset checkpoint")
+	}
+
+	return errors.Wrap(c.repos.Checkpoint.Save(ctx, last.checkpoint),
+		fmt.Sprintf("save checkpoint page num %d", last.checkpoint.Num))
+}
@@ -0,0 +1,342 @@
+package domovita
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/scrape"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
+	"github.com/sku4/ad-parser/pkg/logger"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// Domovita parses html pages of domovita.by
+type Domovita struct {
+	id uint16
+}
+
+func init() {
+	parser.MustRegister(New())
+}
+
+func New() *Domovita {
+	return &Domovita{
+		id: profile.Domovita.ID,
+	}
+}
+
+const (
+	searchURL   = "https://domovita.by/%s/%s/%s?page=%d"
+	sellerOwner = "Собственник"
+	roundPlaces = 2
+	adsCap      = 30
+	m2Are       = 100
+)
+
+type category struct {
+	kind     string
+	slug     string
+	listing  model.Listing
+	property model.PropertyType
+}
+
+type section struct {
+	region configs.Region
+	category
+}
+
+func (c category) Listing() model.Listing {
+	return c.listing
+}
+
+func (c category) Property() model.PropertyType {
+	return c.property
+}
+
+var (
+	// sourceIDRe matches id of object in url like https://domovita.by/minsk/flats/sale/501001
+	sourceIDRe = regexp.MustCompile(`domovita\.by/[\w-]+/(?:flats|houses|offices|land)/(?:sale|rent)/(\d+)`)
+	categories = []category{
+		{"flats", "sale", model.ListingSale, model.PropertyFlat},
+		{"flats", "rent", model.ListingRent, model.PropertyFlat},
+		{"houses", "sale", model.ListingSale, model.PropertyHouse},
+		{"offices", "sale", model.ListingSale, model.PropertyOffice},
+		{"offices", "rent", model.ListingRent, model.PropertyOffice},
+		{"land", "sale", model.ListingSale, model.PropertyLand},
+	}
+	// labels of parameters of objects list and detail page
+	roomsLabel      = "Комнат"
+	floorLabel      = "Этаж"
+	areaLabel       = "Площадь общая"
+	livingLabel     = "Площадь жилая"
+	kitchenLabel    = "Площадь кухни"
+	landLabel       = "Площадь участка"
+	yearLabel       = "Год постройки"
+	materialLabel   = "Материал стен"
+	ceilingLabel    = "Высота потолков"
+	bathroomLabel   = "Санузел"
+	balconyLabel    = "Балкон"
+	renovationLabel = "Ремонт"
+	parkingLabel    = "Парковка"
+)
+
+func (d *Domovita) GetCode() string {
+	return profile.Domovita.Code
+}
+
+func (d *Domovita) GetID() uint16 {
+	return d.id
+}
+
+// SourceID returns id of object by its url
+func (d *Domovita) SourceID(url string) (string, bool) {
+	m := sourceIDRe.FindStringSubmatch(url)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1], true
+}
+
+func (d *Domovita) Auth(ctx context.Context) error {
+	_ = ctx
+	return nil
+}
+
+// SearchArticles parses page of objects list, section ends on page without link to the next page
+func (d *Domovita) SearchArticles(ctx context.Context, page *model.Page) ([]*model.Ad, error) {
+	log := logger.Get()
+
+	domovitaPage := d.getCurrentPage(page)
+	sections := d.sections(ctx)
+	sectionID, ok := search.Index(sections, domovitaPage.Section, section.key)
+	if !ok {
+		return nil, model.ErrLastPage
+	}
+	sec := sections[sectionID]
+	domovitaPage.Section = sec.key()
+
+	doc, err := scrape.Fetch(ctx, fmt.Sprintf(searchURL, sec.region.Name, sec.kind, sec.slug, page.Num))
+	if err != nil {
+		if errors.Is(err, model.ErrTooManyRequests) {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "search page")
+	}
+
+	ads := make([]*model.Ad, 0, adsCap)
+	doc.Find(".found_item").Each(func(_ int, item *goquery.Selection) {
+		link, ok := doc.URL(item.Find("a.found_item__title"), "href")
+		if !ok {
+			log.Warnf("Search %s page %d: object without link", profile.Domovita.Code, page.Num)
+			return
+		}
+		sourceID, ok := d.SourceID(link)
+		if !ok {
+			log.Warnf("Search %s page %d: object without id %s", profile.Domovita.Code, page.Num, link)
+			return
+		}
+		ads = append(ads, d.ad(ctx, doc, item, sec, sourceID, link))
+	})
+
+	page.Next = domovitaPage
+	if doc.Find(`.pagination a[rel="next"]`).Length() == 0 || len(ads) == 0 {
+		if sectionID == len(sections)-1 {
+			return ads, model.ErrLastPage
+		}
+		domovitaPage.Section = sections[sectionID+1].key()
+		page.Num = 0
+	}
+
+	return ads, nil
+}
+
+// ad returns ad of item of objects list
+func (d *Domovita) ad(ctx context.Context, doc *scrape.Document, item *goquery.Selection, sec section, sourceID, link string) *model.Ad {
+	modelAd := &model.Ad{
+		SourceID: sourceID,
+		ExtID:    model.LegacyExtID(link),
+		URL:      link,
+		Listing:  sec.listing,
+		Property: sec.property,
+		Region:   sec.region.Name,
+		Photos:   make([]string, 0),
+	}
+
+	modelAd.Street, modelAd.House = address.Split(ctx, scrape.Find(item, ".found_item__address"), sec.region.Cities...).Ptrs()
+	modelAd.LocLat = coordinate(item, "data-lat")
+	modelAd.LocLong = coordinate(item, "data-lng")
+
+	// price is in byn and approximately in usd, price of rent is per month
+	if price, ok := scrape.Number(scrape.Find(item, ".price__usd")); ok && price > 0 {
+		modelAd.Price = usd(price)
+	}
+	if sec.listing == model.ListingRent {
+		modelAd.PriceMonth, modelAd.Price = modelAd.Price, nil
+		rp := model.RentPeriodLong
+		modelAd.RentPeriod = &rp
+	} else if priceM2, ok := scrape.Number(scrape.Find(item, ".price__m2")); ok && priceM2 > 0 {
+		modelAd.PriceM2 = usd(priceM2)
+	}
+
+	props := scrape.Values(item, ".found_item__props li", ".prop__label", ".prop__value")
+	d.setParams(modelAd, props)
+
+	item.Find(".found_item__photos img").Each(func(_ int, img *goquery.Selection) {
+		if photo, ok := doc.URL(img, "data-src"); ok {
+			modelAd.Photos = append(modelAd.Photos, photo)
+		}
+	})
+
+	seller := scrape.Find(item, ".found_item__seller")
+	owner := seller == sellerOwner
+	modelAd.Owner = &owner
+	if !owner && seller != "" {
+		modelAd.Agency = &seller
+	}
+
+	return modelAd
+}
+
+// setParams sets rooms, floors, areas and year by parameters of objects list or detail page,
+// area of land is in ares
+func (d *Domovita) setParams(modelAd *model.Ad, params map[string]string) {
+	if rooms, ok := scrape.Number(params[roomsLabel]); ok && rooms > 0 {
+		r := uint8(rooms)
+		modelAd.Rooms = &r
+	}
+	// floor is written like "3 из 9"
+	floor := scrape.Numbers(params[floorLabel])
+	for i, dst := range []**uint8{&modelAd.Floor, &modelAd.Floors} {
+		if i < len(floor) && floor[i] > 0 {
+			f := uint8(floor[i])
+			*dst = &f
+		}
+	}
+	for label, dst := range map[string]**float64{
+		areaLabel:    &modelAd.M2Main,
+		livingLabel:  &modelAd.M2Living,
+		kitchenLabel: &modelAd.M2Kitchen,
+	} {
+		if area, ok := scrape.Number(params[label]); ok && area > 0 {
+			*dst = &area
+		}
+	}
+	if land, ok := scrape.Number(params[landLabel]); ok && land > 0 {
+		land *= m2Are
+		modelAd.M2Land = &land
+	}
+	if year, ok := scrape.Number(params[yearLabel]); ok && year > 0 {
+		y := uint16(year)
+		modelAd.Year = &y
+	}
+}
+
+// DownloadArticle sets description, photo gallery, location and attributes of detail page,
+// ad is returned as is with error when detail page is not available
+func (d *Domovita) DownloadArticle(ctx context.Context, modelAd *model.Ad) (*model.Ad, error) {
+	doc, err := scrape.Fetch(ctx, modelAd.URL)
+	if err != nil {
+		if errors.Is(err, model.ErrTooManyRequests) {
+			return modelAd, err
+		}
+		return modelAd, errors.Wrap(err, "object page")
+	}
+
+	object := doc.Find(".object")
+	if description := scrape.Find(object, ".object__description"); description != "" {
+		modelAd.Description = &description
+	}
+
+	photos := make([]string, 0)
+	object.Find("a.gallery__item").Each(func(_ int, a *goquery.Selection) {
+		if photo, ok := doc.URL(a, "href"); ok {
+			photos = append(photos, photo)
+		}
+	})
+	if len(photos) > 0 {
+		modelAd.Photos = photos
+	}
+
+	if m := object.Find("#object-map"); m.Length() > 0 {
+		if lat := coordinate(m, "data-lat"); lat != nil {
+			modelAd.LocLat = lat
+		}
+		if long := coordinate(m, "data-lng"); long != nil {
+			modelAd.LocLong = long
+		}
+	}
+
+	params := scrape.Values(object, ".object-info__row", ".object-info__label", ".object-info__value")
+	d.setParams(modelAd, params)
+	modelAd.Material = scrape.Value(params, materialLabel)
+	modelAd.Bathroom = scrape.Value(params, bathroomLabel)
+	modelAd.Balcony = scrape.Value(params, balconyLabel)
+	modelAd.Renovation = scrape.Value(params, renovationLabel)
+	modelAd.Parking = scrape.Value(params, parkingLabel)
+	if ceiling, ok := scrape.Number(params[ceilingLabel]); ok && ceiling > 0 {
+		modelAd.CeilingHeight = &ceiling
+	}
+
+	seller := model.SellerAgency
+	if modelAd.Owner != nil && *modelAd.Owner {
+		seller = model.SellerOwner
+	}
+	modelAd.Seller = &seller
+	_, phoneHidden := object.Find(".owner-info__phone").Attr("data-hidden")
+	modelAd.PhoneHidden = &phoneHidden
+
+	return modelAd, nil
+}
+
+// coordinate returns coordinate of attribute of element, nil is returned for missed or zero value
+func coordinate(s *goquery.Selection, attr string) *float64 {
+	value, ok := s.Attr(attr)
+	if !ok {
+		return nil
+	}
+	c, err := strconv.ParseFloat(value, 64)
+	if err != nil || c == 0 {
+		return nil
+	}
+
+	return &c
+}
+
+func usd(price float64) *decimal.Decimal {
+	d, err := decimal.NewDecimalFromString(fmt.Sprintf("%.*f", roundPlaces, price))
+	if err != nil {
+		return nil
+	}
+
+	return d
+}
+
+// key returns key of section saved by checkpoint
+func (s section) key() string {
+	return search.Key(s.region, s.category)
+}
+
+func (d *Domovita) sections(ctx context.Context) []section {
+	return search.Sections(ctx, d.GetCode(), categories, func(region configs.Region, c category) section {
+		return section{region, c}
+	})
+}
+
+func (d *Domovita) getCurrentPage(page *model.Page) *Page {
+	domovitaPage := &Page{}
+	if dp, ok := page.Next.(*Page); ok {
+		domovitaPage = dp
+	}
+
+	return domovitaPage
+}
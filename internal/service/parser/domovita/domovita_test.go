@@ -0,0 +1,32 @@
+package domovita
+
+import (
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/replay/replaytest"
+	"github.com/sku4/ad-parser/model"
+)
+
+var testParser = configs.Parser{
+	Listings: []string{model.ListingSale.String(), model.ListingRent.String()},
+	PropertyTypes: []string{
+		model.PropertyFlat.String(), model.PropertyHouse.String(), model.PropertyOffice.String(),
+		model.PropertyCommercial.String(), model.PropertyLand.String(),
+	},
+}
+
+func TestProfile(t *testing.T) {
+	replaytest.Profile{
+		Parser: testParser,
+		New: func() replaytest.Source {
+			return New()
+		},
+		Removed: &model.Ad{SourceID: "9999", URL: "https://domovita.by/minsk/flats/sale/9999"},
+		SourceIDs: []replaytest.SourceID{
+			{URL: "https://domovita.by/minsk/flats/sale/501001", ID: "501001", OK: true},
+			{URL: "https://domovita.by/brest/flats/rent/601001?from=map", ID: "601001", OK: true},
+			{URL: "https://domovita.by/minsk/flats/sale"},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,35 @@
+package domovita
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+)
+
+// Page is section of search, number of page of section is number of model page
+type Page struct {
+	Section string `json:"section"` // key of section
+}
+
+// NextPage decodes pagination data saved by checkpoint of search, checkpoint of section
+// which is not searched any more is not resumed
+func (d *Domovita) NextPage(ctx context.Context, data []byte) (interface{}, error) {
+	domovitaPage := &Page{}
+	if err := json.Unmarshal(data, domovitaPage); err != nil {
+		return nil, errors.Wrap(err, "next page unmarshal")
+	}
+
+	if err := search.Resumed(d.sections(ctx), domovitaPage.Section, section.key); err != nil {
+		return nil, errors.Wrap(err, "next page")
+	}
+
+	return domovitaPage, nil
+}
+
+// LastPage returns page after the last section of search
+func (d *Domovita) LastPage() *model.Page {
+	return &model.Page{Num: 1, Next: &Page{Section: search.LastSection}}
+}
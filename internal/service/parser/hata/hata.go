@@ -0,0 +1,303 @@
+package hata
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/scrape"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
+	"github.com/sku4/ad-parser/pkg/logger"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// Hata parses html pages of hata.by, pages are served in windows-1251
+type Hata struct {
+	id uint16
+}
+
+func init() {
+	parser.MustRegister(New())
+}
+
+func New() *Hata {
+	return &Hata{
+		id: profile.Hata.ID,
+	}
+}
+
+const (
+	searchURL   = "https://www.hata.by/%s-%s/%s/"
+	sellerOwner = "Собственник"
+	roundPlaces = 2
+	adsCap      = 50
+	m2Are       = 100
+)
+
+type category struct {
+	slug     string
+	kind     string
+	listing  model.Listing
+	property model.PropertyType
+}
+
+type section struct {
+	region configs.Region
+	category
+}
+
+func (c category) Listing() model.Listing {
+	return c.listing
+}
+
+func (c category) Property() model.PropertyType {
+	return c.property
+}
+
+var (
+	// sourceIDRe matches id of object in url like https://www.hata.by/sale-flat/2105001/
+	sourceIDRe = regexp.MustCompile(`hata\.by/(?:sale|rent)-(?:flat|house|commercial|land)/(\d+)`)
+	// roomsRe matches count of rooms in title like "2-комнатная квартира"
+	roomsRe    = regexp.MustCompile(`(\d+)-комнатная`)
+	categories = []category{
+		{"sale", "flat", model.ListingSale, model.PropertyFlat},
+		{"rent", "flat", model.ListingRent, model.PropertyFlat},
+		{"sale", "house", model.ListingSale, model.PropertyHouse},
+		{"sale", "commercial", model.ListingSale, model.PropertyCommercial},
+		{"rent", "commercial", model.ListingRent, model.PropertyCommercial},
+		{"sale", "land", model.ListingSale, model.PropertyLand},
+	}
+	// labels of rows of parameters table of detail page
+	materialLabel   = "Материал стен"
+	ceilingLabel    = "Высота потолков"
+	balconyLabel    = "Балкон"
+	renovationLabel = "Ремонт"
+	parkingLabel    = "Парковка"
+)
+
+func (h *Hata) GetCode() string {
+	return profile.Hata.Code
+}
+
+func (h *Hata) GetID() uint16 {
+	return h.id
+}
+
+// SourceID returns id of object by its url
+func (h *Hata) SourceID(url string) (string, bool) {
+	m := sourceIDRe.FindStringSubmatch(url)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1], true
+}
+
+func (h *Hata) Auth(ctx context.Context) error {
+	_ = ctx
+	return nil
+}
+
+// SearchArticles parses page of objects list, the next page is taken from link of pager
+func (h *Hata) SearchArticles(ctx context.Context, page *model.Page) ([]*model.Ad, error) {
+	log := logger.Get()
+
+	hataPage := h.getCurrentPage(page)
+	sections := h.sections(ctx)
+	sectionID, ok := search.Index(sections, hataPage.Section, section.key)
+	if !ok {
+		return nil, model.ErrLastPage
+	}
+	sec := sections[sectionID]
+	hataPage.Section = sec.key()
+
+	pageURL := hataPage.URL
+	if pageURL == "" {
+		pageURL = fmt.Sprintf(searchURL, sec.slug, sec.kind, sec.region.Name)
+	}
+
+	doc, err := scrape.Fetch(ctx, pageURL)
+	if err != nil {
+		if errors.Is(err, model.ErrTooManyRequests) {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "search page")
+	}
+
+	ads := make([]*model.Ad, 0, adsCap)
+	doc.Find(".b-list__item").Each(func(_ int, item *goquery.Selection) {
+		link, ok := doc.URL(item.Find("a.b-list__title"), "href")
+		if !ok {
+			log.Warnf("Search %s page %d: object without link", profile.Hata.Code, page.Num)
+			return
+		}
+		sourceID, ok := h.SourceID(link)
+		if !ok {
+			log.Warnf("Search %s page %d: object without id %s", profile.Hata.Code, page.Num, link)
+			return
+		}
+		ads = append(ads, h.ad(ctx, doc, item, sec, sourceID, link))
+	})
+
+	next, ok := doc.URL(doc.Find(".b-pager a.b-pager__next"), "href")
+	if !ok || len(ads) == 0 {
+		if sectionID == len(sections)-1 {
+			return ads, model.ErrLastPage
+		}
+		hataPage.Section = sections[sectionID+1].key()
+		next = ""
+		page.Num = 0
+	}
+	hataPage.URL = next
+	page.Next = hataPage
+
+	return ads, nil
+}
+
+// ad returns ad of item of objects list
+func (h *Hata) ad(ctx context.Context, doc *scrape.Document, item *goquery.Selection, sec section, sourceID, link string) *model.Ad {
+	modelAd := &model.Ad{
+		SourceID: sourceID,
+		ExtID:    model.LegacyExtID(link),
+		URL:      link,
+		Listing:  sec.listing,
+		Property: sec.property,
+		Region:   sec.region.Name,
+		Photos:   make([]string, 0, 1),
+	}
+
+	modelAd.Street, modelAd.House = address.Split(ctx, scrape.Find(item, ".b-list__address"), sec.region.Cities...).Ptrs()
+
+	if m := roomsRe.FindStringSubmatch(scrape.Find(item, "a.b-list__title")); m != nil {
+		if rooms, ok := scrape.Number(m[1]); ok && rooms > 0 {
+			r := uint8(rooms)
+			modelAd.Rooms = &r
+		}
+	}
+
+	// price is in usd, price of rent is per month
+	priceText := scrape.Find(item, ".b-list__price")
+	if price, ok := scrape.Number(priceText); ok && price > 0 && strings.Contains(priceText, "$") {
+		d, err := decimal.NewDecimalFromString(fmt.Sprintf("%.*f", roundPlaces, price))
+		if err == nil {
+			modelAd.Price = d
+		}
+	}
+	if sec.listing == model.ListingRent {
+		modelAd.PriceMonth, modelAd.Price = modelAd.Price, nil
+		rp := model.RentPeriodLong
+		modelAd.RentPeriod = &rp
+	}
+
+	// area is total, living and kitchen, floor is floor and count of floors
+	area := scrape.Numbers(scrape.Find(item, `[data-param="area"]`))
+	for i, dst := range []**float64{&modelAd.M2Main, &modelAd.M2Living, &modelAd.M2Kitchen} {
+		if i < len(area) && area[i] > 0 {
+			a := area[i]
+			*dst = &a
+		}
+	}
+	floor := scrape.Numbers(scrape.Find(item, `[data-param="floor"]`))
+	for i, dst := range []**uint8{&modelAd.Floor, &modelAd.Floors} {
+		if i < len(floor) && floor[i] > 0 {
+			f := uint8(floor[i])
+			*dst = &f
+		}
+	}
+	// area of land is in ares
+	if land, ok := scrape.Number(scrape.Find(item, `[data-param="land"]`)); ok && land > 0 {
+		land *= m2Are
+		modelAd.M2Land = &land
+	}
+	if year, ok := scrape.Number(scrape.Find(item, `[data-param="year"]`)); ok && year > 0 {
+		y := uint16(year)
+		modelAd.Year = &y
+	}
+
+	if photo, ok := doc.URL(item.Find(".b-list__photo img"), "src"); ok {
+		modelAd.Photos = append(modelAd.Photos, photo)
+	}
+
+	seller := scrape.Find(item, ".b-list__seller")
+	owner := seller == sellerOwner
+	modelAd.Owner = &owner
+	if !owner && seller != "" {
+		modelAd.Agency = &seller
+	}
+
+	return modelAd
+}
+
+// DownloadArticle sets description, photo gallery and attributes of detail page,
+// ad is returned as is with error when detail page is not available
+func (h *Hata) DownloadArticle(ctx context.Context, modelAd *model.Ad) (*model.Ad, error) {
+	doc, err := scrape.Fetch(ctx, modelAd.URL)
+	if err != nil {
+		if errors.Is(err, model.ErrTooManyRequests) {
+			return modelAd, err
+		}
+		return modelAd, errors.Wrap(err, "object page")
+	}
+
+	object := doc.Find(".b-object")
+	if description := scrape.Find(object, ".b-object__description"); description != "" {
+		modelAd.Description = &description
+	}
+
+	photos := make([]string, 0)
+	object.Find(".b-object__gallery a").Each(func(_ int, a *goquery.Selection) {
+		if photo, ok := doc.URL(a, "href"); ok {
+			photos = append(photos, photo)
+		}
+	})
+	if len(photos) > 0 {
+		modelAd.Photos = photos
+	}
+
+	params := scrape.Values(object, ".b-object__params tr", "th", "td")
+	modelAd.Material = scrape.Value(params, materialLabel)
+	modelAd.Balcony = scrape.Value(params, balconyLabel)
+	modelAd.Renovation = scrape.Value(params, renovationLabel)
+	modelAd.Parking = scrape.Value(params, parkingLabel)
+	if ceiling, ok := scrape.Number(params[ceilingLabel]); ok && ceiling > 0 {
+		modelAd.CeilingHeight = &ceiling
+	}
+
+	seller := model.SellerAgency
+	if modelAd.Owner != nil && *modelAd.Owner {
+		seller = model.SellerOwner
+	}
+	modelAd.Seller = &seller
+	phoneHidden := object.Find(".b-object__phone").HasClass("b-object__phone_hidden")
+	modelAd.PhoneHidden = &phoneHidden
+
+	return modelAd, nil
+}
+
+// key returns key of section saved by checkpoint
+func (s section) key() string {
+	return search.Key(s.region, s.category)
+}
+
+func (h *Hata) sections(ctx context.Context) []section {
+	return search.Sections(ctx, h.GetCode(), categories, func(region configs.Region, c category) section {
+		return section{region, c}
+	})
+}
+
+func (h *Hata) getCurrentPage(page *model.Page) *Page {
+	hataPage := &Page{}
+	if hp, ok := page.Next.(*Page); ok {
+		hataPage = hp
+	}
+
+	return hataPage
+}
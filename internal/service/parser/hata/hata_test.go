@@ -0,0 +1,32 @@
+package hata
+
+import (
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/replay/replaytest"
+	"github.com/sku4/ad-parser/model"
+)
+
+var testParser = configs.Parser{
+	Listings: []string{model.ListingSale.String(), model.ListingRent.String()},
+	PropertyTypes: []string{
+		model.PropertyFlat.String(), model.PropertyHouse.String(), model.PropertyOffice.String(),
+		model.PropertyCommercial.String(), model.PropertyLand.String(),
+	},
+}
+
+func TestProfile(t *testing.T) {
+	replaytest.Profile{
+		Parser: testParser,
+		New: func() replaytest.Source {
+			return New()
+		},
+		Removed: &model.Ad{SourceID: "9999", URL: "https://www.hata.by/sale-flat/9999/"},
+		SourceIDs: []replaytest.SourceID{
+			{URL: "https://www.hata.by/sale-flat/2105001/", ID: "2105001", OK: true},
+			{URL: "https://hata.by/rent-flat/3105001", ID: "3105001", OK: true},
+			{URL: "https://www.hata.by/sale-flat/minsk/"},
+		},
+	}.Run(t)
+}
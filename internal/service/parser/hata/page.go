@@ -0,0 +1,36 @@
+package hata
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+)
+
+// Page is section and url of the next page of section taken from pager
+type Page struct {
+	Section string `json:"section"` // key of section
+	URL     string `json:"url"`
+}
+
+// NextPage decodes pagination data saved by checkpoint of search, checkpoint of section
+// which is not searched any more is not resumed
+func (h *Hata) NextPage(ctx context.Context, data []byte) (interface{}, error) {
+	hataPage := &Page{}
+	if err := json.Unmarshal(data, hataPage); err != nil {
+		return nil, errors.Wrap(err, "next page unmarshal")
+	}
+
+	if err := search.Resumed(h.sections(ctx), hataPage.Section, section.key); err != nil {
+		return nil, errors.Wrap(err, "next page")
+	}
+
+	return hataPage, nil
+}
+
+// LastPage returns page after the last section of search
+func (h *Hata) LastPage() *model.Page {
+	return &model.Page{Num: 1, Next: &Page{Section: search.LastSection}}
+}
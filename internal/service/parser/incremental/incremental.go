@@ -0,0 +1,28 @@
+package incremental
+
+import (
+	"context"
+
+	"github.com/sku4/ad-parser/model"
+)
+
+// Checker reports whether all ads are saved already and are not changed since they were saved
+type Checker func(ctx context.Context, ads []*model.Ad) bool
+
+type checkerKey struct{}
+
+// Set returns context of incremental search, search is full when checker is not set
+func Set(ctx context.Context, c Checker) context.Context {
+	return context.WithValue(ctx, checkerKey{}, c)
+}
+
+// Unchanged reports whether all ads of page are known and unchanged, profile which sorts
+// search results by update time stops search of section on such page as the rest is known too
+func Unchanged(ctx context.Context, ads []*model.Ad) bool {
+	c, ok := ctx.Value(checkerKey{}).(Checker)
+	if !ok || len(ads) == 0 {
+		return false
+	}
+
+	return c(ctx, ads)
+}
@@ -0,0 +1,40 @@
+package incremental
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sku4/ad-parser/model"
+)
+
+func TestUnchanged(t *testing.T) {
+	ads := []*model.Ad{{SourceID: "1"}, {SourceID: "2"}}
+	checked := 0
+	checker := func(_ context.Context, checkedAds []*model.Ad) bool {
+		checked++
+		return len(checkedAds) == len(ads)
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		ads       []*model.Ad
+		unchanged bool
+		checked   int
+	}{
+		{"full search", context.Background(), ads, false, 0},
+		{"empty page", Set(context.Background(), checker), nil, false, 0},
+		{"checked page", Set(context.Background(), checker), ads, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked = 0
+			if got := Unchanged(tt.ctx, tt.ads); got != tt.unchanged {
+				t.Errorf("unchanged %v, want %v", got, tt.unchanged)
+			}
+			if checked != tt.checked {
+				t.Errorf("checker is called %d times, want %d", checked, tt.checked)
+			}
+		})
+	}
+}
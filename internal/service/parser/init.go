@@ -1,22 +1,99 @@
 package parser
 
 import (
-	"github.com/sku4/ad-parser/internal/service/parser/kufar"
-	"github.com/sku4/ad-parser/internal/service/parser/onliner"
-	"github.com/sku4/ad-parser/internal/service/parser/realt"
+	"fmt"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/jsonapi"
+	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
 )
 
 var (
-	codeProfiles = map[string]iProfile{}
+	codeProfiles = map[string]Source{}
 )
 
-func init() {
-	profiles := []iProfile{
-		kufar.New(),
-		onliner.New(),
-		realt.New(),
+// Register adds parser of registered profile, id and code of parser must be the same
+// as ones of profile, it must be called before service is run
+func Register(source Source) error {
+	p, ok := profile.ByCode(source.GetCode())
+	if !ok {
+		return fmt.Errorf("register parser '%s': %w", source.GetCode(), model.ErrProfileNotFound)
+	}
+	if p.ID != source.GetID() {
+		return fmt.Errorf("register parser '%s' %d of profile %d: %w",
+			source.GetCode(), source.GetID(), p.ID, profile.ErrProfileConflict)
+	}
+	if _, ok = codeProfiles[p.Code]; ok {
+		return fmt.Errorf("register parser '%s': %w", p.Code, profile.ErrProfileExists)
+	}
+	codeProfiles[p.Code] = source
+
+	return nil
+}
+
+// MustRegister adds parser of registered profile and panics on error, it is used by init of sources
+func MustRegister(source Source) {
+	if err := Register(source); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterMappings registers profiles and parsers of JSON API sources described by mapping files
+func RegisterMappings(files []string) error {
+	for _, file := range files {
+		m, err := jsonapi.Load(file)
+		if err != nil {
+			return err
+		}
+		source, err := jsonapi.New(m)
+		if err != nil {
+			return err
+		}
+		err = profile.Register(&profile.Profile{
+			ID:      m.ID,
+			Code:    m.Code,
+			Name:    m.Name,
+			BaseURL: m.BaseURL,
+			Enabled: m.Enabled,
+		})
+		if err != nil {
+			return err
+		}
+		if err = Register(source); err != nil {
+			return err
+		}
 	}
-	for _, profile := range profiles {
-		codeProfiles[profile.GetCode()] = profile
+
+	return nil
+}
+
+// Declare registers profiles declared by config, parser of profile must be registered
+// by Register to parse declared profile
+func Declare(sources []configs.Source) error {
+	for _, source := range sources {
+		err := profile.Declare(&profile.Profile{
+			ID:      source.ID,
+			Code:    source.Code,
+			Name:    source.Name,
+			BaseURL: source.BaseURL,
+			Enabled: source.Enabled,
+		})
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+// enabled reports whether profile is registered with parser and it is not disabled
+func enabled(code string) (bool, error) {
+	_, okParser := codeProfiles[code]
+	p, ok := profile.ByCode(code)
+	if !okParser || !ok {
+		return false, fmt.Errorf("parser '%s': %w", code, model.ErrProfileNotFound)
+	}
+
+	return p.Enabled, nil
 }
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/repository"
+	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
+)
+
+type codeProfile struct {
+	testProfile
+	code string
+	id   uint16
+}
+
+func (cp codeProfile) GetCode() string { return cp.code }
+func (cp codeProfile) GetID() uint16   { return cp.id }
+
+func TestRegister(t *testing.T) {
+	profile.MustRegister(&profile.Profile{ID: 200, Code: "test200"})
+
+	tests := []struct {
+		name   string
+		source Source
+		err    error
+	}{
+		{"registered profile", codeProfile{code: "test200", id: 200}, nil},
+		{"registered parser", codeProfile{code: "test200", id: 200}, profile.ErrProfileExists},
+		{"unknown profile", codeProfile{code: "test201", id: 201}, model.ErrProfileNotFound},
+		{"other id of profile", codeProfile{code: "kufar", id: 200}, profile.ErrProfileConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Register(tt.source); !errors.Is(err, tt.err) {
+				t.Fatalf("register error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRunOnceDisabled(t *testing.T) {
+	err := Declare([]configs.Source{{ID: 210, Code: "test210", Enabled: false}})
+	if err != nil {
+		t.Fatalf("declare: %s", err)
+	}
+	if err = Register(codeProfile{code: "test210", id: 210}); err != nil {
+		t.Fatalf("register: %s", err)
+	}
+
+	ctx := configs.Set(context.Background(), &configs.Config{})
+	s := NewService(&repository.Repository{Ad: &batchRepo{}})
+	if _, err = s.RunOnce(ctx, []string{"test210"}, CleanSkip); !errors.Is(err, model.ErrProfileDisabled) {
+		t.Errorf("run once error %v, want disabled profile", err)
+	}
+	if _, err = s.RunOnce(ctx, []string{"test211"}, CleanSkip); !errors.Is(err, model.ErrProfileNotFound) {
+		t.Errorf("run once error %v, want not found profile", err)
+	}
+}
+
+func TestRegisterMappings(t *testing.T) {
+	if err := RegisterMappings([]string{"jsonapi/testdata/total.yml"}); err != nil {
+		t.Fatalf("register mappings: %s", err)
+	}
+	if ok, err := enabled("example-total"); err != nil || !ok {
+		t.Errorf("mapping profile is not enabled: %v", err)
+	}
+	if err := RegisterMappings([]string{"jsonapi/testdata/total.yml"}); !errors.Is(err, profile.ErrProfileExists) {
+		t.Errorf("register mapping twice error %v, want exists", err)
+	}
+	if err := RegisterMappings([]string{"jsonapi/testdata/missing.yml"}); err == nil {
+		t.Error("missing mapping is registered")
+	}
+}
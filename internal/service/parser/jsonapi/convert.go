@@ -0,0 +1,230 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sku4/ad-parser/model"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// converters of values of fields
+const (
+	converterCents  = "cents"
+	converterUnix   = "unix"
+	converterUnixMs = "unix_ms"
+	converterTime   = "time"
+	roundPlaces     = 2
+	centsInUnit     = 100
+)
+
+// setter sets value of field to ad, false is returned when value can not be converted
+type setter func(ad *model.Ad, v interface{}, f *Field) bool
+
+// setters of fields of ad by names of fields in mapping
+var setters = map[string]setter{
+	"source_id":   func(ad *model.Ad, v interface{}, f *Field) bool { return setString(&ad.SourceID, v, f) },
+	"url":         func(ad *model.Ad, v interface{}, f *Field) bool { return setString(&ad.URL, v, f) },
+	"street":      func(ad *model.Ad, v interface{}, f *Field) bool { return setStringPtr(&ad.Street, v, f) },
+	"house":       func(ad *model.Ad, v interface{}, f *Field) bool { return setStringPtr(&ad.House, v, f) },
+	"bathroom":    func(ad *model.Ad, v interface{}, f *Field) bool { return setStringPtr(&ad.Bathroom, v, f) },
+	"agency":      func(ad *model.Ad, v interface{}, f *Field) bool { return setStringPtr(&ad.Agency, v, f) },
+	"description": func(ad *model.Ad, v interface{}, f *Field) bool { return setStringPtr(&ad.Description, v, f) },
+	"loc_lat":     func(ad *model.Ad, v interface{}, f *Field) bool { return setFloat(&ad.LocLat, v, f) },
+	"loc_long":    func(ad *model.Ad, v interface{}, f *Field) bool { return setFloat(&ad.LocLong, v, f) },
+	"m2_main":     func(ad *model.Ad, v interface{}, f *Field) bool { return setFloat(&ad.M2Main, v, f) },
+	"m2_living":   func(ad *model.Ad, v interface{}, f *Field) bool { return setFloat(&ad.M2Living, v, f) },
+	"m2_kitchen":  func(ad *model.Ad, v interface{}, f *Field) bool { return setFloat(&ad.M2Kitchen, v, f) },
+	"m2_land":     func(ad *model.Ad, v interface{}, f *Field) bool { return setFloat(&ad.M2Land, v, f) },
+	"price":       func(ad *model.Ad, v interface{}, f *Field) bool { return setDecimal(&ad.Price, v, f) },
+	"price_month": func(ad *model.Ad, v interface{}, f *Field) bool { return setDecimal(&ad.PriceMonth, v, f) },
+	"rooms":       func(ad *model.Ad, v interface{}, f *Field) bool { return setUint8(&ad.Rooms, v, f) },
+	"floor":       func(ad *model.Ad, v interface{}, f *Field) bool { return setUint8(&ad.Floor, v, f) },
+	"floors":      func(ad *model.Ad, v interface{}, f *Field) bool { return setUint8(&ad.Floors, v, f) },
+	"year": func(ad *model.Ad, v interface{}, f *Field) bool {
+		n, ok := toFloat(v, f)
+		if !ok || n <= 0 || n > float64(^uint16(0)) {
+			return false
+		}
+		year := uint16(n)
+		ad.Year = &year
+		return true
+	},
+	"owner": func(ad *model.Ad, v interface{}, _ *Field) bool {
+		owner, ok := v.(bool)
+		if ok {
+			ad.Owner = &owner
+		}
+		return ok
+	},
+	"photos": func(ad *model.Ad, v interface{}, f *Field) bool {
+		items, ok := v.([]interface{})
+		if !ok {
+			items = []interface{}{v}
+		}
+		photos := make([]string, 0, len(items))
+		for _, item := range items {
+			if photo, okPhoto := toString(item, f); okPhoto {
+				photos = append(photos, photo)
+			}
+		}
+		ad.Photos = photos
+		return len(photos) > 0
+	},
+	"created": func(ad *model.Ad, v interface{}, f *Field) bool {
+		t, ok := toTime(v, f)
+		if !ok {
+			return false
+		}
+		created, err := datetime.NewDatetime(t.UTC())
+		if err != nil {
+			return false
+		}
+		ad.Created = created
+		return true
+	},
+	"updated": func(ad *model.Ad, v interface{}, f *Field) bool {
+		t, ok := toTime(v, f)
+		if ok {
+			updated := t.UTC()
+			ad.SourceUpdated = &updated
+		}
+		return ok
+	},
+}
+
+func setString(dst *string, v interface{}, f *Field) bool {
+	s, ok := toString(v, f)
+	if ok {
+		*dst = s
+	}
+
+	return ok
+}
+
+func setStringPtr(dst **string, v interface{}, f *Field) bool {
+	s, ok := toString(v, f)
+	if ok {
+		*dst = &s
+	}
+
+	return ok
+}
+
+func setFloat(dst **float64, v interface{}, f *Field) bool {
+	n, ok := toFloat(v, f)
+	if !ok || n == 0 {
+		return false
+	}
+	*dst = &n
+
+	return true
+}
+
+func setUint8(dst **uint8, v interface{}, f *Field) bool {
+	n, ok := toFloat(v, f)
+	if !ok || n <= 0 || n > float64(^uint8(0)) {
+		return false
+	}
+	u := uint8(n)
+	*dst = &u
+
+	return true
+}
+
+func setDecimal(dst **decimal.Decimal, v interface{}, f *Field) bool {
+	n, ok := toFloat(v, f)
+	if !ok || n <= 0 {
+		return false
+	}
+	d, err := decimal.NewDecimalFromString(strconv.FormatFloat(n, 'f', roundPlaces, 64))
+	if err != nil {
+		return false
+	}
+	*dst = d
+
+	return true
+}
+
+// toString returns string of value, format of field is applied to not empty string
+func toString(v interface{}, f *Field) (string, bool) {
+	var s string
+	switch value := v.(type) {
+	case string:
+		s = strings.TrimSpace(value)
+	case json.Number:
+		s = value.String()
+	case bool:
+		s = strconv.FormatBool(value)
+	default:
+		return "", false
+	}
+	if s == "" {
+		return "", false
+	}
+	if f.Format != "" {
+		s = fmt.Sprintf(f.Format, s)
+	}
+
+	return s, true
+}
+
+// toFloat returns number of value, numbers in strings are converted too
+func toFloat(v interface{}, f *Field) (float64, bool) {
+	var n float64
+	var err error
+	switch value := v.(type) {
+	case json.Number:
+		n, err = value.Float64()
+	case string:
+		n, err = strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", "."), 64)
+	default:
+		return 0, false
+	}
+	if err != nil {
+		return 0, false
+	}
+	if f.Converter == converterCents {
+		n /= centsInUnit
+	}
+
+	return n, true
+}
+
+// toTime returns time of value by converter of field, time in string is parsed
+// by format of field which is RFC3339 by default
+func toTime(v interface{}, f *Field) (time.Time, bool) {
+	switch f.Converter {
+	case converterUnix, converterUnixMs:
+		n, ok := v.(json.Number)
+		if !ok {
+			return time.Time{}, false
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		if f.Converter == converterUnixMs {
+			return time.UnixMilli(i), true
+		}
+		return time.Unix(i, 0), true
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return time.Time{}, false
+		}
+		layout := time.RFC3339
+		if f.Format != "" {
+			layout = f.Format
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+}
@@ -0,0 +1,222 @@
+package jsonapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
+	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/logger"
+)
+
+// JSONAPI is profile of source with JSON API which is parsed by mapping without code of source
+type JSONAPI struct {
+	*compiled
+}
+
+// urlVars are fields of template of search url
+type urlVars struct {
+	Page   int
+	Offset int
+	Size   int
+	Cursor string
+}
+
+func New(m *Mapping) (*JSONAPI, error) {
+	c, err := compile(m)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("mapping '%s'", m.Code))
+	}
+
+	return &JSONAPI{
+		compiled: c,
+	}, nil
+}
+
+func (j *JSONAPI) GetCode() string {
+	return j.Code
+}
+
+func (j *JSONAPI) GetID() uint16 {
+	return j.ID
+}
+
+// SourceID returns id of ad by its url when mapping has source id pattern
+func (j *JSONAPI) SourceID(url string) (string, bool) {
+	if j.sourceID == nil {
+		return "", false
+	}
+	m := j.sourceID.FindStringSubmatch(url)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1], true
+}
+
+func (j *JSONAPI) Auth(ctx context.Context) error {
+	_ = ctx
+	return nil
+}
+
+func (j *JSONAPI) SearchArticles(ctx context.Context, page *model.Page) ([]*model.Ad, error) {
+	log := logger.Get()
+
+	var parserCfg configs.Parser
+	if cfg := configs.Get(ctx); cfg != nil {
+		parserCfg = cfg.Parser
+	}
+	if !parserCfg.ListingEnabled(j.listing.String()) || !parserCfg.PropertyEnabled(j.property.String()) {
+		return nil, model.ErrLastPage
+	}
+
+	jsonPage := j.getCurrentPage(page)
+	url := &bytes.Buffer{}
+	err := j.url.Execute(url, urlVars{
+		Page:   j.Pagination.Start + page.Num - 1,
+		Offset: (page.Num - 1) * j.Pagination.Size,
+		Size:   j.Pagination.Size,
+		Cursor: jsonPage.Cursor,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "search url template")
+	}
+
+	resp, err := j.request(ctx, url.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "search url request")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, model.ErrTooManyRequests
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("search status %d", resp.StatusCode)
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err = dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("body response decode %s: %w", j.GetCode(), err)
+	}
+
+	value, _ := j.results.lookup(doc)
+	results, _ := value.([]interface{})
+	ads := make([]*model.Ad, 0, len(results))
+	for i, item := range results {
+		modelAd, ok := j.ad(ctx, item)
+		if !ok {
+			log.Warnf("Search %s page %d: result %d has no source id or url", j.GetCode(), page.Num, i)
+			continue
+		}
+		ads = append(ads, modelAd)
+	}
+
+	last := len(results) == 0
+	if j.lastPage != nil {
+		value, _ = j.lastPage.lookup(doc)
+		if isLast, ok := value.(bool); ok && isLast {
+			last = true
+		}
+	}
+	switch j.Pagination.Type {
+	case PaginationCursor:
+		value, _ = j.cursor.lookup(doc)
+		cursor, ok := toString(value, &Field{})
+		if !ok {
+			last = true
+		}
+		jsonPage.Cursor = cursor
+	case PaginationTotal:
+		value, _ = j.total.lookup(doc)
+		total, ok := toFloat(value, &Field{})
+		if !ok || float64(page.Num*j.Pagination.Size) >= total {
+			last = true
+		}
+	}
+	page.Next = jsonPage
+
+	if last {
+		return ads, model.ErrLastPage
+	}
+
+	return ads, nil
+}
+
+// DownloadArticle returns ad as it is, mapping describes search results only
+func (j *JSONAPI) DownloadArticle(ctx context.Context, modelAd *model.Ad) (*model.Ad, error) {
+	_ = ctx
+	return modelAd, nil
+}
+
+// ad maps item of results to ad, false is returned when item has no source id or url
+func (j *JSONAPI) ad(ctx context.Context, item interface{}) (*model.Ad, bool) {
+	modelAd := &model.Ad{
+		Listing:  j.listing,
+		Property: j.property,
+		Region:   j.Region,
+	}
+	for name, f := range j.Fields {
+		value, ok := f.path.lookup(item)
+		if !ok || value == nil {
+			continue
+		}
+		setters[name](modelAd, value, &f)
+	}
+	if modelAd.SourceID == "" || modelAd.URL == "" {
+		return nil, false
+	}
+
+	var streetName, house string
+	if modelAd.Street != nil {
+		streetName = *modelAd.Street
+	}
+	if modelAd.House != nil {
+		house = *modelAd.House
+	}
+	modelAd.Street, modelAd.House = address.Normalize(ctx, streetName, house).Ptrs()
+	modelAd.ExtID = model.LegacyExtID(modelAd.URL)
+	if j.listing == model.ListingRent {
+		rp := model.RentPeriodLong
+		modelAd.RentPeriod = &rp
+	}
+
+	return modelAd, true
+}
+
+func (j *JSONAPI) request(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error create request: %w", err)
+	}
+	for key, value := range j.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := transport.Get(ctx).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error request body page: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (j *JSONAPI) getCurrentPage(page *model.Page) *Page {
+	jsonPage := &Page{}
+	if jp, ok := page.Next.(*Page); ok {
+		jsonPage = jp
+	}
+
+	return jsonPage
+}
@@ -0,0 +1,148 @@
+package jsonapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/replay"
+	"github.com/sku4/ad-parser/internal/service/parser/replay/replaytest"
+	"github.com/sku4/ad-parser/model"
+)
+
+func testContext() context.Context {
+	return replaytest.Context(replaytest.FixturesDir, configs.Parser{
+		Listings:      []string{model.ListingSale.String(), model.ListingRent.String()},
+		PropertyTypes: []string{model.PropertyFlat.String(), model.PropertyOffice.String()},
+	})
+}
+
+func testProfile(t *testing.T, name string) *JSONAPI {
+	t.Helper()
+
+	m, err := Load("testdata/" + name + ".yml")
+	if err != nil {
+		t.Fatalf("load mapping: %s", err)
+	}
+	j, err := New(m)
+	if err != nil {
+		t.Fatalf("new profile: %s", err)
+	}
+
+	return j
+}
+
+func TestSearchArticles(t *testing.T) {
+	for _, name := range []string{PaginationPage, PaginationCursor, PaginationTotal} {
+		t.Run(name, func(t *testing.T) {
+			pages, err := replay.Search(testContext(), testProfile(t, name), replaytest.MaxPages)
+			if err != nil {
+				t.Fatalf("search articles: %s", err)
+			}
+
+			replaytest.Golden(t, "testdata/"+name+".golden.json", pages)
+		})
+	}
+}
+
+func TestSearchArticlesListingDisabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser configs.Parser
+	}{
+		{"listing", configs.Parser{}},
+		{"property type", configs.Parser{
+			Listings:      []string{model.ListingRent.String()},
+			PropertyTypes: []string{model.PropertyFlat.String()},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := configs.Set(testContext(), &configs.Config{Parser: tt.parser})
+
+			ads, err := testProfile(t, PaginationCursor).SearchArticles(ctx, &model.Page{Num: 1})
+			if err != model.ErrLastPage || len(ads) != 0 {
+				t.Fatalf("expected last page without ads of disabled %s, got %d ads, %v", tt.name, len(ads), err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	m, err := Load("testdata/" + PaginationTotal + ".yml")
+	if err != nil {
+		t.Fatalf("load mapping: %s", err)
+	}
+	if !m.Enabled || m.Listing != model.ListingSale.String() || m.PropertyType != model.PropertyFlat.String() ||
+		m.Region != configs.DefaultRegion.Name || m.Pagination.Start != 1 {
+		t.Errorf("defaults are not set: %+v", m)
+	}
+}
+
+func TestNew(t *testing.T) {
+	valid := func() *Mapping {
+		return &Mapping{
+			Code:         "test",
+			URL:          "https://api.example.com/?page={{.Page}}",
+			Pagination:   Pagination{Type: PaginationPage},
+			Results:      "$.items",
+			Listing:      model.ListingSale.String(),
+			PropertyType: model.PropertyFlat.String(),
+			Fields: map[string]Field{
+				"source_id": {Path: "$.id"},
+				"url":       {Path: "$.url"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(m *Mapping)
+		valid  bool
+	}{
+		{"valid", func(*Mapping) {}, true},
+		{"invalid url template", func(m *Mapping) { m.URL = "{{.Page" }, false},
+		{"invalid results path", func(m *Mapping) { m.Results = "items" }, false},
+		{"unknown pagination", func(m *Mapping) { m.Pagination.Type = "offset" }, false},
+		{"cursor without path", func(m *Mapping) { m.Pagination.Type = PaginationCursor }, false},
+		{"total without size", func(m *Mapping) {
+			m.Pagination = Pagination{Type: PaginationTotal, Total: "$.total"}
+		}, false},
+		{"unknown listing", func(m *Mapping) { m.Listing = "daily" }, false},
+		{"unknown property type", func(m *Mapping) { m.PropertyType = "castle" }, false},
+		{"without url field", func(m *Mapping) { delete(m.Fields, "url") }, false},
+		{"unknown field", func(m *Mapping) { m.Fields["color"] = Field{Path: "$.color"} }, false},
+		{"unknown converter", func(m *Mapping) { m.Fields["price"] = Field{Path: "$.price", Converter: "usd"} }, false},
+		{"source id pattern without group", func(m *Mapping) { m.SourceIDPattern = `\d+` }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid()
+			tt.modify(m)
+			if _, err := New(m); (err == nil) != tt.valid {
+				t.Errorf("new profile error %v, valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestSourceID(t *testing.T) {
+	j := testProfile(t, PaginationPage)
+	tests := []struct {
+		url      string
+		sourceID string
+		ok       bool
+	}{
+		{"https://example.com/flat/101", "101", true},
+		{"https://example.com/rent/r1", "", false},
+	}
+	for _, tt := range tests {
+		sourceID, ok := j.SourceID(tt.url)
+		if sourceID != tt.sourceID || ok != tt.ok {
+			t.Errorf("source id of %s is %s %v, want %s %v", tt.url, sourceID, ok, tt.sourceID, tt.ok)
+		}
+	}
+	if _, ok := testProfile(t, PaginationTotal).SourceID("https://example.com/o/a1"); ok {
+		t.Error("source id is found without pattern")
+	}
+}
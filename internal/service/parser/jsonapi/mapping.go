@@ -0,0 +1,188 @@
+package jsonapi
+
+import (
+	"fmt"
+	"regexp"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/model"
+	"github.com/spf13/viper"
+)
+
+// strategies of pagination
+const (
+	PaginationPage   = "page"
+	PaginationCursor = "cursor"
+	PaginationTotal  = "total"
+)
+
+// Mapping describes JSON API of source, it is read from YAML file
+type Mapping struct {
+	ID      uint16 `mapstructure:"id"`
+	Code    string `mapstructure:"code"`
+	Name    string `mapstructure:"name"`
+	BaseURL string `mapstructure:"base_url"`
+	Enabled bool   `mapstructure:"enabled"`
+	// URL is text/template of search url with fields Page, Offset, Size and Cursor
+	URL        string            `mapstructure:"url"`
+	Headers    map[string]string `mapstructure:"headers"`
+	Pagination Pagination        `mapstructure:"pagination"`
+	// Results is path of array of ads in response
+	Results string `mapstructure:"results"`
+	// LastPage is path of value which is true on the last page
+	LastPage string `mapstructure:"last_page"`
+	// SourceIDPattern is regexp of url of ad with source id in the first group
+	SourceIDPattern string           `mapstructure:"source_id_pattern"`
+	Listing         string           `mapstructure:"listing"`
+	PropertyType    string           `mapstructure:"property_type"`
+	Region          string           `mapstructure:"region"`
+	Fields          map[string]Field `mapstructure:"fields"`
+}
+
+// Pagination is page number, cursor or total count strategy of pages
+type Pagination struct {
+	Type string `mapstructure:"type"`
+	// Start is number of the first page
+	Start int `mapstructure:"start"`
+	Size  int `mapstructure:"size"`
+	// Cursor is path of cursor of the next page
+	Cursor string `mapstructure:"cursor"`
+	// Total is path of total count of ads
+	Total string `mapstructure:"total"`
+}
+
+// Field is path of value of ad field in item of results, format is time layout
+// for time converters and fmt format of string for other fields
+type Field struct {
+	Path      string `mapstructure:"path"`
+	Converter string `mapstructure:"converter"`
+	Format    string `mapstructure:"format"`
+
+	path path
+}
+
+// Load reads mapping from YAML file
+func Load(file string) (*Mapping, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	v.SetDefault("enabled", true)
+	v.SetDefault("pagination.type", PaginationPage)
+	v.SetDefault("pagination.start", 1)
+	v.SetDefault("listing", model.ListingSale.String())
+	v.SetDefault("property_type", model.PropertyFlat.String())
+	v.SetDefault("region", configs.DefaultRegion.Name)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "load mapping")
+	}
+
+	var m Mapping
+	if err := v.Unmarshal(&m); err != nil {
+		return nil, errors.Wrap(err, "load mapping")
+	}
+
+	return &m, nil
+}
+
+// compiled is mapping with parsed paths and templates
+type compiled struct {
+	*Mapping
+	url      *template.Template
+	results  path
+	lastPage path
+	cursor   path
+	total    path
+	sourceID *regexp.Regexp
+	listing  model.Listing
+	property model.PropertyType
+}
+
+func compile(m *Mapping) (*compiled, error) {
+	c := &compiled{Mapping: m}
+
+	var err error
+	if c.url, err = template.New(m.Code).Option("missingkey=error").Parse(m.URL); err != nil {
+		return nil, errors.Wrap(err, "url")
+	}
+	if c.results, err = parsePath(m.Results); err != nil {
+		return nil, errors.Wrap(err, "results")
+	}
+	if c.lastPage, err = optionalPath(m.LastPage); err != nil {
+		return nil, errors.Wrap(err, "last page")
+	}
+
+	switch m.Pagination.Type {
+	case PaginationPage:
+	case PaginationCursor:
+		if c.cursor, err = parsePath(m.Pagination.Cursor); err != nil {
+			return nil, errors.Wrap(err, "pagination cursor")
+		}
+	case PaginationTotal:
+		if m.Pagination.Size <= 0 {
+			return nil, errors.New("pagination size must be positive for total pagination")
+		}
+		if c.total, err = parsePath(m.Pagination.Total); err != nil {
+			return nil, errors.Wrap(err, "pagination total")
+		}
+	default:
+		return nil, fmt.Errorf("unknown pagination '%s'", m.Pagination.Type)
+	}
+
+	if m.SourceIDPattern != "" {
+		if c.sourceID, err = regexp.Compile(m.SourceIDPattern); err != nil {
+			return nil, errors.Wrap(err, "source id pattern")
+		}
+		if c.sourceID.NumSubexp() < 1 {
+			return nil, errors.New("source id pattern must have group of source id")
+		}
+	}
+
+	for _, l := range []model.Listing{model.ListingSale, model.ListingRent} {
+		if l.String() == m.Listing {
+			c.listing = l
+		}
+	}
+	if c.listing == 0 {
+		return nil, fmt.Errorf("unknown listing '%s'", m.Listing)
+	}
+
+	for p := model.PropertyFlat; p <= model.PropertyLand; p++ {
+		if p.String() == m.PropertyType {
+			c.property = p
+		}
+	}
+	if c.property == 0 {
+		return nil, fmt.Errorf("unknown property type '%s'", m.PropertyType)
+	}
+
+	for _, required := range []string{"source_id", "url"} {
+		if _, ok := m.Fields[required]; !ok {
+			return nil, fmt.Errorf("field '%s' is required", required)
+		}
+	}
+	for name, f := range m.Fields {
+		if _, ok := setters[name]; !ok {
+			return nil, fmt.Errorf("unknown field '%s'", name)
+		}
+		switch f.Converter {
+		case "", converterCents, converterUnix, converterUnixMs, converterTime:
+		default:
+			return nil, fmt.Errorf("unknown converter '%s' of field '%s'", f.Converter, name)
+		}
+		if f.path, err = parsePath(f.Path); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("field '%s'", name))
+		}
+		m.Fields[name] = f
+	}
+
+	return c, nil
+}
+
+func optionalPath(expr string) (path, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	return parsePath(expr)
+}
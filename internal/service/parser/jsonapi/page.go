@@ -0,0 +1,22 @@
+package jsonapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+type Page struct {
+	Cursor string `json:"cursor"`
+}
+
+// NextPage decodes pagination data saved by checkpoint of search
+func (j *JSONAPI) NextPage(_ context.Context, data []byte) (interface{}, error) {
+	jsonPage := &Page{}
+	if err := json.Unmarshal(data, jsonPage); err != nil {
+		return nil, errors.Wrap(err, "next page unmarshal")
+	}
+
+	return jsonPage, nil
+}
@@ -0,0 +1,110 @@
+package jsonapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is member name or array index of path, wildcard selects all items of array
+type step struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// path is JSONPath expression of subset supported by mapping:
+// $.data.items, $.images[0], $.images[*].url, $['content-type']
+type path []step
+
+func parsePath(expr string) (path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("path '%s' must start with $", expr)
+	}
+
+	p := make(path, 0)
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path '%s' has empty member", expr)
+			}
+			p = append(p, step{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path '%s' has unclosed bracket", expr)
+			}
+			s, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("path '%s': %w", expr, err)
+			}
+			p = append(p, s)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path '%s' has unexpected '%c'", expr, rest[0])
+		}
+	}
+
+	return p, nil
+}
+
+func parseBracket(s string) (step, error) {
+	switch {
+	case s == "*":
+		return step{wildcard: true}, nil
+	case len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]:
+		return step{key: s[1 : len(s)-1]}, nil
+	}
+
+	index, err := strconv.Atoi(s)
+	if err != nil || index < 0 {
+		return step{}, fmt.Errorf("invalid index [%s]", s)
+	}
+
+	return step{index: index, isIndex: true}, nil
+}
+
+// lookup returns value of path in decoded JSON, values of wildcard are returned as array
+func (p path) lookup(v interface{}) (interface{}, bool) {
+	for i, s := range p {
+		switch {
+		case s.wildcard:
+			items, ok := v.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			values := make([]interface{}, 0, len(items))
+			for _, item := range items {
+				if value, okItem := p[i+1:].lookup(item); okItem && value != nil {
+					values = append(values, value)
+				}
+			}
+			return values, true
+		case s.isIndex:
+			items, ok := v.([]interface{})
+			if !ok || s.index >= len(items) {
+				return nil, false
+			}
+			v = items[s.index]
+		default:
+			members, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if v, ok = members[s.key]; !ok {
+				return nil, false
+			}
+		}
+	}
+
+	return v, true
+}
@@ -0,0 +1,51 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(`{
+		"data": {"items": [{"id": 1, "images": [{"url": "a"}, {"url": "b"}, {}]}]},
+		"content-type": "flat"
+	}`))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+
+	tests := []struct {
+		expr  string
+		value interface{}
+		ok    bool
+	}{
+		{"$.data.items[0].id", json.Number("1"), true},
+		{"$['content-type']", "flat", true},
+		{"$.data.items[0].images[*].url", []interface{}{"a", "b"}, true},
+		{"$.data.items[1].id", nil, false},
+		{"$.data.missing", nil, false},
+		{"$.data.items.id", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := parsePath(tt.expr)
+			if err != nil {
+				t.Fatalf("parse path: %s", err)
+			}
+			value, ok := p.lookup(doc)
+			if ok != tt.ok || !reflect.DeepEqual(value, tt.value) {
+				t.Errorf("lookup %v %v, want %v %v", value, ok, tt.value, tt.ok)
+			}
+		})
+	}
+
+	for _, expr := range []string{"data.items", "$.", "$.items[", "$.items[-1]", "$x"} {
+		if _, err := parsePath(expr); err == nil {
+			t.Errorf("path '%s' is parsed", expr)
+		}
+	}
+}
@@ -4,54 +4,106 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"hash/crc32"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	dec "github.com/shopspring/decimal"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
 	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
 	"github.com/sku4/ad-parser/pkg/logger"
 	"github.com/tarantool/go-tarantool/v2/datetime"
 	"github.com/tarantool/go-tarantool/v2/decimal"
 )
 
 type Kufar struct {
-	crcTable *crc32.Table
+	id uint16
+}
+
+func init() {
+	parser.MustRegister(New())
 }
 
 func New() *Kufar {
 	return &Kufar{
-		crcTable: crc32.MakeTable(crc32.IEEE),
+		id: profile.Kufar.ID,
 	}
 }
 
 const (
-	profileID = 1
 	searchURL = "" +
 		"https://api.kufar.by/search-api/v1/search/rendered-paginated" +
 		"?cat=%s&cur=USD&cursor=%s" +
-		"&gtsy=country-belarus~province-minsk~locality-minsk&lang=ru&size=200&typ=sell"
-	yamsURL     = "https://yams.kufar.by/api/v1/kufar-ads/images/%s/%s.jpg?rule=list_thumbs_2x"
-	rmsURL      = "https://rms.kufar.by/v1/list_thumbs_2x/%s"
+		"&gtsy=%s&lang=ru&size=200&typ=%s"
+	itemURL     = "https://api.kufar.by/search-api/v2/item/%s/rendered"
+	yamsURL     = "https://yams.kufar.by/api/v1/kufar-ads/images/%s/%s.jpg?rule=%s"
+	rmsURL      = "https://rms.kufar.by/v1/%s/%s"
+	ruleList    = "list_thumbs_2x"
+	ruleGallery = "gallery"
 	roundPlaces = 2
 	roundNumber = 100
+	m2Are       = 100
 )
 
+type category struct {
+	id       string
+	typ      string
+	listing  model.Listing
+	property model.PropertyType
+}
+
+type section struct {
+	region configs.Region
+	category
+}
+
+func (c category) Listing() model.Listing {
+	return c.listing
+}
+
+func (c category) Property() model.PropertyType {
+	return c.property
+}
+
 var (
-	categories = []string{
-		"1010",
-		"1020",
+	// sourceIDRe matches id of ad at the end of ad link like https://re.kufar.by/vi/minsk/kupit/kvartiru/1001
+	sourceIDRe = regexp.MustCompile(`kufar\.by/vi/(?:[^?#]*/)?(\d+)(?:[?#]|$)`)
+	categories = []category{
+		{"1010", "sell", model.ListingSale, model.PropertyFlat},
+		{"1020", "sell", model.ListingSale, model.PropertyHouse},
+		{"1010", "let", model.ListingRent, model.PropertyFlat},
+		{"1020", "let", model.ListingRent, model.PropertyHouse},
+		{"1050", "sell", model.ListingSale, model.PropertyCommercial},
+		{"1050", "let", model.ListingRent, model.PropertyCommercial},
+		{"1040", "sell", model.ListingSale, model.PropertyGarage},
+		{"1040", "let", model.ListingRent, model.PropertyGarage},
+		{"1080", "sell", model.ListingSale, model.PropertyLand},
 	}
 )
 
 func (k *Kufar) GetCode() string {
-	return "kufar"
+	return profile.Kufar.Code
 }
 
 func (k *Kufar) GetID() uint16 {
-	return profileID
+	return k.id
+}
+
+// SourceID returns id of ad by its url, it is used to migrate ads saved before source id
+func (k *Kufar) SourceID(url string) (string, bool) {
+	m := sourceIDRe.FindStringSubmatch(url)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1], true
 }
 
 func (k *Kufar) Auth(ctx context.Context) error {
@@ -59,13 +111,23 @@ func (k *Kufar) Auth(ctx context.Context) error {
 	return nil
 }
 
+// SearchArticles always searches all pages of section, search is not incremental as results
+// are sorted by list time which is not changed by update of price of ad
+//
 //nolint:gocyclo,funlen
 func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.Ad, error) {
 	log := logger.Get()
 
 	kufarPage := k.getCurrentPage(page)
+	sections := k.sections(ctx)
+	sectionID, ok := search.Index(sections, kufarPage.Section, section.key)
+	if !ok {
+		return nil, model.ErrLastPage
+	}
+	sec := sections[sectionID]
+	kufarPage.Section = sec.key()
 
-	url := fmt.Sprintf(searchURL, categories[kufarPage.CategoryID], kufarPage.Cursor)
+	url := fmt.Sprintf(searchURL, sec.id, kufarPage.Cursor, sec.region.Locality, sec.typ)
 	resp, err := k.request(ctx, url)
 	if err != nil {
 		return nil, errors.Wrap(err, "search url request")
@@ -87,26 +149,18 @@ func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 
 	ads := make([]*model.Ad, 0)
 	for _, kufarAd := range kufarResp.Ads {
-		var house *string
-		address := ""
+		addr := ""
 		for _, param := range kufarAd.AccountParameters {
 			if param.P == "address" {
-				address = param.V
+				addr = param.V
 			}
 		}
-		var street *string
-		streetSplit, houseSplit := k.addressSplit(address)
-		if streetSplit != "" {
-			street = &streetSplit
-		}
-		if houseSplit != "" {
-			house = &houseSplit
-		}
+		street, house := address.Split(ctx, addr, sec.region.Cities...).Ptrs()
 
 		var locLat, locLong *float64
 		var rooms, floor, floors *uint8
 		var year *uint16
-		var m2Main, m2Living, m2Kitchen *float64
+		var m2Main, m2Living, m2Kitchen, m2Land *float64
 		var bathroom *string
 		for _, param := range kufarAd.AdParameters {
 			switch param.P {
@@ -159,6 +213,12 @@ func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 				if m, ok := param.V.(float64); ok {
 					m2Kitchen = &m
 				}
+			case "size_area":
+				// area of land is in ares
+				if m, ok := param.V.(float64); ok {
+					m *= m2Are
+					m2Land = &m
+				}
 			case "bathroom":
 				if b, ok := param.Vl.(string); ok {
 					bathroom = &b
@@ -166,7 +226,7 @@ func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 			}
 		}
 
-		var price *decimal.Decimal
+		var price, priceMonth *decimal.Decimal
 		var errPrice error
 		if kufarAd.PriceUsd != nil && *kufarAd.PriceUsd != "" {
 			price, errPrice = decimal.NewDecimalFromString(*kufarAd.PriceUsd)
@@ -177,45 +237,52 @@ func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 			}
 		}
 
-		photos := make([]string, 0, len(kufarAd.Images))
-		for _, i := range kufarAd.Images {
-			if i.MediaStorage == "rms" {
-				photos = append(photos, fmt.Sprintf(rmsURL, i.Path))
-			} else if i.MediaStorage == "yams" && i.ID != "" {
-				yams := []rune(i.ID)
-				if len(yams) > 1 {
-					photos = append(photos, fmt.Sprintf(yamsURL, string(yams[:2]), i.ID))
-				}
-			}
+		var rentPeriod *model.RentPeriod
+		if sec.listing == model.ListingRent {
+			priceMonth, price = price, nil
+			rp := model.RentPeriodLong
+			rentPeriod = &rp
 		}
+
+		owner := !kufarAd.CompanyAd
+
+		// the full gallery is set by detail page
+		photos := k.photos(kufarAd.Images, ruleList)
 		if len(photos) > 0 {
 			photos = photos[0:1]
 		}
 
-		extID := crc32.Checksum([]byte(kufarAd.AdLink), k.crcTable)
 		created, errTime := datetime.NewDatetime(kufarAd.ListTime.UTC())
 		if errTime != nil {
 			log.Warnf("error time convert %v to datetime: %s", kufarAd.ListTime, errTime)
 		}
 
 		modelAd := &model.Ad{
-			ExtID:     extID,
-			Created:   created,
-			URL:       kufarAd.AdLink,
-			Street:    street,
-			House:     house,
-			LocLat:    locLat,
-			LocLong:   locLong,
-			Price:     price,
-			Rooms:     rooms,
-			Floor:     floor,
-			Floors:    floors,
-			Year:      year,
-			Photos:    photos,
-			M2Main:    m2Main,
-			M2Living:  m2Living,
-			M2Kitchen: m2Kitchen,
-			Bathroom:  bathroom,
+			SourceID:   strconv.Itoa(kufarAd.AdID),
+			ExtID:      model.LegacyExtID(kufarAd.AdLink),
+			Created:    created,
+			URL:        kufarAd.AdLink,
+			Street:     street,
+			House:      house,
+			LocLat:     locLat,
+			LocLong:    locLong,
+			Price:      price,
+			Rooms:      rooms,
+			Floor:      floor,
+			Floors:     floors,
+			Year:       year,
+			Photos:     photos,
+			M2Main:     m2Main,
+			M2Living:   m2Living,
+			M2Kitchen:  m2Kitchen,
+			M2Land:     m2Land,
+			Bathroom:   bathroom,
+			Listing:    sec.listing,
+			Property:   sec.property,
+			Region:     sec.region.Name,
+			PriceMonth: priceMonth,
+			RentPeriod: rentPeriod,
+			Owner:      &owner,
 		}
 		ads = append(ads, modelAd)
 	}
@@ -229,10 +296,10 @@ func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 	}
 
 	if next == "" {
-		if kufarPage.CategoryID == len(categories)-1 {
+		if sectionID == len(sections)-1 {
 			return ads, model.ErrLastPage
 		}
-		kufarPage.CategoryID++
+		kufarPage.Section = sections[sectionID+1].key()
 	}
 
 	kufarPage.Cursor = next
@@ -241,32 +308,115 @@ func (k *Kufar) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 	return ads, nil
 }
 
+// DownloadArticle sets description, photo gallery and attributes of detail page,
+// ad is returned as is with error when detail page is not available
 func (k *Kufar) DownloadArticle(ctx context.Context, modelAd *model.Ad) (*model.Ad, error) {
-	_ = ctx
+	resp, err := k.request(ctx, fmt.Sprintf(itemURL, modelAd.SourceID))
+	if err != nil {
+		return modelAd, errors.Wrap(err, "item url request")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return modelAd, model.ErrTooManyRequests
+	}
+	if resp.StatusCode != http.StatusOK {
+		return modelAd, fmt.Errorf("item %s status %d: %w", modelAd.SourceID, resp.StatusCode, model.ErrArticleStatus)
+	}
+
+	var kufarResp RespItem
+	err = json.NewDecoder(resp.Body).Decode(&kufarResp)
+	if err != nil {
+		return modelAd, fmt.Errorf("item response decode %s: %w", k.GetCode(), err)
+	}
+	item := kufarResp.Result
+
+	if body := strings.TrimSpace(item.Body); body != "" {
+		modelAd.Description = &body
+	}
+	if photos := k.photos(item.Images, ruleGallery); len(photos) > 0 {
+		modelAd.Photos = photos
+	}
+
+	seller := model.SellerOwner
+	if item.CompanyAd {
+		seller = model.SellerAgency
+	}
+	modelAd.Seller = &seller
+	owner := !item.CompanyAd
+	modelAd.Owner = &owner
+	phoneHidden := item.PhoneHidden
+	modelAd.PhoneHidden = &phoneHidden
+
+	for _, param := range item.AdParameters {
+		switch param.P {
+		case "house_type":
+			modelAd.Material = k.label(param)
+		case "ceiling_height":
+			modelAd.CeilingHeight = k.float(param)
+		case "balcony":
+			modelAd.Balcony = k.label(param)
+		case "re_repair":
+			modelAd.Renovation = k.label(param)
+		case "parking":
+			modelAd.Parking = k.label(param)
+		}
+	}
+
 	return modelAd, nil
 }
 
-func (k *Kufar) addressSplit(address string) (street, house string) {
-	street = address
-	addressSplit := strings.Split(address, ",")
-	if len(addressSplit) > 1 {
-		street = addressSplit[0]
-		house = addressSplit[1]
-	} else if len(addressSplit) > 0 {
-		street = addressSplit[0]
+// photos returns urls of images resized by rule
+func (k *Kufar) photos(images []Image, rule string) []string {
+	photos := make([]string, 0, len(images))
+	for _, i := range images {
+		if i.MediaStorage == "rms" {
+			photos = append(photos, fmt.Sprintf(rmsURL, rule, i.Path))
+		} else if i.MediaStorage == "yams" && i.ID != "" {
+			yams := []rune(i.ID)
+			if len(yams) > 1 {
+				photos = append(photos, fmt.Sprintf(yamsURL, string(yams[:2]), i.ID, rule))
+			}
+		}
 	}
 
-	return strings.TrimSpace(street), strings.TrimSpace(house)
+	return photos
+}
+
+// label returns readable value of parameter
+func (k *Kufar) label(param AdParameter) *string {
+	if l, ok := param.Vl.(string); ok && l != "" {
+		return &l
+	}
+
+	return nil
+}
+
+// float returns numeric value of parameter which is sent as number or string
+func (k *Kufar) float(param AdParameter) *float64 {
+	switch v := param.V.(type) {
+	case float64:
+		return &v
+	case string:
+		f, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64)
+		if err == nil {
+			return &f
+		}
+	}
+
+	return nil
 }
 
 func (k *Kufar) request(ctx context.Context, url string) (*http.Response, error) {
-	var client http.Client
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error create request: %w", err)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := transport.Get(ctx).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error request body page: %w", err)
 	}
@@ -274,6 +424,17 @@ func (k *Kufar) request(ctx context.Context, url string) (*http.Response, error)
 	return resp, nil
 }
 
+// key returns key of section saved by checkpoint
+func (s section) key() string {
+	return search.Key(s.region, s.category)
+}
+
+func (k *Kufar) sections(ctx context.Context) []section {
+	return search.Sections(ctx, k.GetCode(), categories, func(region configs.Region, c category) section {
+		return section{region, c}
+	})
+}
+
 func (k *Kufar) getCurrentPage(page *model.Page) *Page {
 	kufarPage := &Page{}
 	if kp, ok := page.Next.(*Page); ok {
@@ -0,0 +1,91 @@
+package kufar
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/replay/replaytest"
+	"github.com/sku4/ad-parser/model"
+)
+
+var testParser = configs.Parser{
+	Listings: []string{model.ListingSale.String(), model.ListingRent.String()},
+	PropertyTypes: []string{
+		model.PropertyFlat.String(), model.PropertyHouse.String(), model.PropertyCommercial.String(),
+		model.PropertyGarage.String(), model.PropertyLand.String(),
+	},
+}
+
+func testContext() context.Context {
+	return replaytest.Context(replaytest.FixturesDir, testParser)
+}
+
+func TestProfile(t *testing.T) {
+	replaytest.Profile{
+		Parser: testParser,
+		New: func() replaytest.Source {
+			return New()
+		},
+		Removed: &model.Ad{SourceID: "9999", URL: "https://re.kufar.by/vi/9999"},
+		SourceIDs: []replaytest.SourceID{
+			{URL: "https://re.kufar.by/vi/minsk/kupit/kvartiru/1001", ID: "1001", OK: true},
+			{URL: "https://re.kufar.by/vi/1001?rank=1", ID: "1001", OK: true},
+			{URL: "https://re.kufar.by/l/minsk/kupit/kvartiru"},
+		},
+	}.Run(t)
+}
+
+func TestSearchArticlesFromCheckpoint(t *testing.T) {
+	ctx := testContext()
+	k := New()
+	page := &model.Page{Num: 1}
+	if _, err := k.SearchArticles(ctx, page); err != nil {
+		t.Fatalf("search first page: %s", err)
+	}
+	page.Num++
+
+	cp, err := page.Checkpoint(k.GetID(), time.Now(), false)
+	if err != nil {
+		t.Fatalf("checkpoint: %s", err)
+	}
+	next, err := k.NextPage(ctx, cp.Next)
+	if err != nil {
+		t.Fatalf("next page: %s", err)
+	}
+	if !reflect.DeepEqual(next, page.Next) {
+		t.Fatalf("decoded page %+v, want %+v", next, page.Next)
+	}
+
+	want, errWant := k.SearchArticles(ctx, page)
+	got, errGot := k.SearchArticles(ctx, &model.Page{Num: cp.Num, Next: next})
+	if !errors.Is(errGot, errWant) || !reflect.DeepEqual(got, want) {
+		t.Fatalf("search from checkpoint differs: %d ads (%v), want %d ads (%v)", len(got), errGot, len(want), errWant)
+	}
+}
+
+func TestNextPageOfRemovedSection(t *testing.T) {
+	saleOnly := testParser
+	saleOnly.Listings = []string{model.ListingSale.String()}
+	ctx := replaytest.Context(replaytest.FixturesDir, saleOnly)
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"searched section", `{"section":"minsk:sale:house","cursor":"c2"}`, nil},
+		{"disabled listing", `{"section":"minsk:rent:flat","cursor":"c2"}`, model.ErrSectionNotFound},
+		{"index of section", `{"section_id":2,"cursor":"c2"}`, model.ErrSectionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New().NextPage(ctx, []byte(tt.data)); !errors.Is(err, tt.err) {
+				t.Errorf("next page error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
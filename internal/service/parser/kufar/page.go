@@ -1,6 +1,35 @@
 package kufar
 
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+)
+
 type Page struct {
-	CategoryID int
-	Cursor     string
+	Section string `json:"section"` // key of section
+	Cursor  string `json:"cursor"`
+}
+
+// NextPage decodes pagination data saved by checkpoint of search, checkpoint of section
+// which is not searched any more is not resumed
+func (k *Kufar) NextPage(ctx context.Context, data []byte) (interface{}, error) {
+	kufarPage := &Page{}
+	if err := json.Unmarshal(data, kufarPage); err != nil {
+		return nil, errors.Wrap(err, "next page unmarshal")
+	}
+
+	if err := search.Resumed(k.sections(ctx), kufarPage.Section, section.key); err != nil {
+		return nil, errors.Wrap(err, "next page")
+	}
+
+	return kufarPage, nil
+}
+
+// LastPage returns page after the last section of search
+func (k *Kufar) LastPage() *model.Page {
+	return &model.Page{Num: 1, Next: &Page{Section: search.LastSection}}
 }
@@ -2,39 +2,45 @@ package kufar
 
 import "time"
 
+type AccountParameter struct {
+	Pl string `json:"pl"`
+	Vl string `json:"vl"`
+	P  string `json:"p"`
+	V  string `json:"v"`
+	Pu string `json:"pu"`
+}
+
+type AdParameter struct {
+	Pl string      `json:"pl"`
+	Vl interface{} `json:"vl"`
+	P  string      `json:"p"`
+	V  interface{} `json:"v"`
+	Pu string      `json:"pu"`
+}
+
+type Image struct {
+	ID           string `json:"id"`
+	MediaStorage string `json:"media_storage"`
+	YamsStorage  bool   `json:"yams_storage"`
+	Path         string `json:"path,omitempty"`
+}
+
 type Resp struct {
 	Ads []struct {
-		AccountID         int `json:"account_id"`
-		AccountParameters []struct {
-			Pl string `json:"pl"`
-			Vl string `json:"vl"`
-			P  string `json:"p"`
-			V  string `json:"v"`
-			Pu string `json:"pu"`
-		} `json:"account_parameters"`
-		AdID         int    `json:"ad_id"`
-		AdLink       string `json:"ad_link"`
-		AdParameters []struct {
-			Pl string      `json:"pl"`
-			Vl interface{} `json:"vl"`
-			P  string      `json:"p"`
-			V  interface{} `json:"v"`
-			Pu string      `json:"pu"`
-		} `json:"ad_parameters"`
-		Body      string `json:"body"`
-		Category  string `json:"category"`
-		CompanyAd bool   `json:"company_ad"`
-		Currency  string `json:"currency"`
-		Images    []struct {
-			ID           string `json:"id"`
-			MediaStorage string `json:"media_storage"`
-			YamsStorage  bool   `json:"yams_storage"`
-			Path         string `json:"path,omitempty"`
-		} `json:"images"`
-		ListID       int       `json:"list_id"`
-		ListTime     time.Time `json:"list_time"`
-		MessageID    string    `json:"message_id"`
-		PaidServices struct {
+		AccountID         int                `json:"account_id"`
+		AccountParameters []AccountParameter `json:"account_parameters"`
+		AdID              int                `json:"ad_id"`
+		AdLink            string             `json:"ad_link"`
+		AdParameters      []AdParameter      `json:"ad_parameters"`
+		Body              string             `json:"body"`
+		Category          string             `json:"category"`
+		CompanyAd         bool               `json:"company_ad"`
+		Currency          string             `json:"currency"`
+		Images            []Image            `json:"images"`
+		ListID            int                `json:"list_id"`
+		ListTime          time.Time          `json:"list_time"`
+		MessageID         string             `json:"message_id"`
+		PaidServices      struct {
 			Halva     bool        `json:"halva"`
 			Highlight bool        `json:"highlight"`
 			Polepos   bool        `json:"polepos"`
@@ -56,3 +62,16 @@ type Resp struct {
 	} `json:"pagination"`
 	Total int `json:"total"`
 }
+
+// RespItem is response of detail page of ad
+type RespItem struct {
+	Result struct {
+		AdID              int                `json:"ad_id"`
+		AccountParameters []AccountParameter `json:"account_parameters"`
+		AdParameters      []AdParameter      `json:"ad_parameters"`
+		Body              string             `json:"body"`
+		CompanyAd         bool               `json:"company_ad"`
+		Images            []Image            `json:"images"`
+		PhoneHidden       bool               `json:"phone_hidden"`
+	} `json:"result"`
+}
@@ -0,0 +1,111 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sku4/ad-parser/configs"
+)
+
+const (
+	defaultRPS      = 2
+	defaultMinRPS   = 0.1
+	defaultBurst    = 1
+	defaultDecrease = 0.5
+	defaultIncrease = 0.05
+)
+
+// Limiter is token bucket which slows down on too many requests responses
+// and gradually recovers the rate on success
+type Limiter struct {
+	mu     sync.Mutex
+	cfg    configs.RateLimit
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func New(cfg configs.RateLimit) *Limiter {
+	if cfg.RPS <= 0 {
+		cfg.RPS = defaultRPS
+	}
+	if cfg.MinRPS <= 0 || cfg.MinRPS > cfg.RPS {
+		cfg.MinRPS = min(defaultMinRPS, cfg.RPS)
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = defaultBurst
+	}
+	if cfg.Decrease <= 0 || cfg.Decrease >= 1 {
+		cfg.Decrease = defaultDecrease
+	}
+	if cfg.Increase <= 0 {
+		cfg.Increase = defaultIncrease
+	}
+
+	return &Limiter{
+		cfg:    cfg,
+		rate:   cfg.RPS,
+		tokens: float64(cfg.Burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until token is available or context is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill(time.Now())
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	return nil
+}
+
+// Throttle decreases rate after too many requests response
+func (l *Limiter) Throttle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	l.rate = max(l.rate*l.cfg.Decrease, l.cfg.MinRPS)
+	l.tokens = min(l.tokens, 0)
+}
+
+// Recover increases rate after success response
+func (l *Limiter) Recover() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	l.rate = min(l.rate+l.cfg.Increase, l.cfg.RPS)
+}
+
+// Rate returns current requests per second rate
+func (l *Limiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
+
+func (l *Limiter) refill(now time.Time) {
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, float64(l.cfg.Burst))
+	l.last = now
+}
@@ -0,0 +1,82 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sku4/ad-parser/configs"
+)
+
+func TestThrottleRecover(t *testing.T) {
+	l := New(configs.RateLimit{RPS: 4, MinRPS: 1, Burst: 1, Decrease: 0.5, Increase: 1})
+
+	steps := []struct {
+		name string
+		step func()
+		rate float64
+	}{
+		{"throttle", l.Throttle, 2},
+		{"throttle", l.Throttle, 1},
+		{"throttle to min rate", l.Throttle, 1},
+		{"recover", l.Recover, 2},
+		{"recover", l.Recover, 3},
+		{"recover", l.Recover, 4},
+		{"recover to max rate", l.Recover, 4},
+	}
+	for i, s := range steps {
+		s.step()
+		if got := l.Rate(); got != s.rate {
+			t.Fatalf("step %d %s: rate %v, want %v", i, s.name, got, s.rate)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	l := New(configs.RateLimit{})
+	if l.Rate() != defaultRPS {
+		t.Fatalf("rate %v, want %v", l.Rate(), defaultRPS)
+	}
+	l.Throttle()
+	if want := defaultRPS * defaultDecrease; l.Rate() != want {
+		t.Fatalf("throttled rate %v, want %v", l.Rate(), want)
+	}
+}
+
+func TestRefill(t *testing.T) {
+	l := New(configs.RateLimit{RPS: 2, Burst: 3})
+	now := time.Now()
+	l.last, l.tokens = now, 0
+
+	l.refill(now.Add(time.Second / 2))
+	if l.tokens != 1 {
+		t.Fatalf("tokens after half second %v, want 1", l.tokens)
+	}
+	l.refill(now.Add(time.Minute))
+	if l.tokens != 3 {
+		t.Fatalf("tokens after minute %v, want burst 3", l.tokens)
+	}
+}
+
+func TestThrottleDropsTokens(t *testing.T) {
+	l := New(configs.RateLimit{RPS: 1000, Burst: 5})
+	l.Throttle()
+	if l.tokens > 0 {
+		t.Fatalf("tokens after throttle %v, want not more than 0", l.tokens)
+	}
+}
+
+func TestWait(t *testing.T) {
+	l := New(configs.RateLimit{RPS: 0.1, Burst: 1})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("wait of burst token: %s", err)
+	}
+
+	// the next token is available in 10 seconds, wait ends with context
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
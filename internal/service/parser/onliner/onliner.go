@@ -4,51 +4,119 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"hash/crc32"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
 	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
 	"github.com/sku4/ad-parser/pkg/logger"
 	"github.com/tarantool/go-tarantool/v2/datetime"
 	"github.com/tarantool/go-tarantool/v2/decimal"
 )
 
 type Onliner struct {
-	crcTable *crc32.Table
+	id uint16
+}
+
+func init() {
+	parser.MustRegister(New())
 }
 
 func New() *Onliner {
 	return &Onliner{
-		crcTable: crc32.MakeTable(crc32.IEEE),
+		id: profile.Onliner.ID,
 	}
 }
 
 const (
-	profileID = 2
 	searchURL = "" +
-		"https://r.onliner.by/sdapi/pk.api/search/apartments" +
-		"?bounds[lb][lat]=53.822171699379794" +
-		"&bounds[lb][long]=27.36090453127423" +
-		"&bounds[rt][lat]=53.97823316350124" +
-		"&bounds[rt][long]=27.73193546111799" +
+		"https://r.onliner.by/sdapi/%s/search/apartments" +
+		"?bounds[lb][lat]=%s" +
+		"&bounds[lb][long]=%s" +
+		"&bounds[rt][lat]=%s" +
+		"&bounds[rt][long]=%s" +
 		"&page=%d&limit=750"
+	apartmentURL  = "https://r.onliner.by/sdapi/%s/apartments/%s"
+	sellerOwner   = "owner"
+	sellerAgent   = "agent"
+	sellerBuilder = "builder"
 )
 
+type category struct {
+	api      string
+	listing  model.Listing
+	property model.PropertyType
+}
+
+type section struct {
+	region configs.Region
+	category
+}
+
+func (c category) Listing() model.Listing {
+	return c.listing
+}
+
+func (c category) Property() model.PropertyType {
+	return c.property
+}
+
 var (
-	delCities = []string{
-		"Минск", "Беларусь", "Minsk",
+	// sourceIDRe matches section and id of apartment, ids of sale and rent sections are not shared
+	sourceIDRe = regexp.MustCompile(`onliner\.by/(pk|ak)/apartments/(\d+)`)
+	categories = []category{
+		{"pk.api", model.ListingSale, model.PropertyFlat},
+		{"ak.api", model.ListingRent, model.PropertyFlat},
+	}
+	// labels of codes of detail page, unknown code is kept as is
+	wallingLabels = map[string]string{
+		"panel":    "Панельный",
+		"brick":    "Кирпичный",
+		"monolith": "Монолитный",
+		"block":    "Блочный",
+	}
+	balconyLabels = map[string]string{
+		"balcony": "Балкон",
+		"loggia":  "Лоджия",
+		"none":    "Нет",
+	}
+	renovationLabels = map[string]string{
+		"none":     "Без отделки",
+		"cosmetic": "Косметический",
+		"euro":     "Евроремонт",
+		"design":   "Дизайнерский",
+	}
+	parkingLabels = map[string]string{
+		"yard":        "Во дворе",
+		"underground": "Подземный паркинг",
+		"garage":      "Гараж",
 	}
 )
 
 func (o *Onliner) GetCode() string {
-	return "onliner"
+	return profile.Onliner.Code
 }
 
 func (o *Onliner) GetID() uint16 {
-	return profileID
+	return o.id
+}
+
+// SourceID returns id of ad by its url, it is used to migrate ads saved before source id
+func (o *Onliner) SourceID(url string) (string, bool) {
+	m := sourceIDRe.FindStringSubmatch(url)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1] + "/" + m[2], true
 }
 
 func (o *Onliner) Auth(ctx context.Context) error {
@@ -56,11 +124,26 @@ func (o *Onliner) Auth(ctx context.Context) error {
 	return nil
 }
 
+// SearchArticles always searches all pages of section, search is not incremental as results
+// are in default order of site which is not order by update time of apartment
+//
 //nolint:gosec
 func (o *Onliner) SearchArticles(ctx context.Context, page *model.Page) ([]*model.Ad, error) {
 	log := logger.Get()
 
-	url := fmt.Sprintf(searchURL, page.Num)
+	onlinerPage := o.getCurrentPage(page)
+	sections := o.sections(ctx)
+	sectionID, ok := search.Index(sections, onlinerPage.Section, section.key)
+	if !ok {
+		return nil, model.ErrLastPage
+	}
+	sec := sections[sectionID]
+	onlinerPage.Section = sec.key()
+
+	bbox := sec.region.Bbox
+	url := fmt.Sprintf(searchURL, sec.api,
+		o.formatCoord(bbox.LbLat), o.formatCoord(bbox.LbLong),
+		o.formatCoord(bbox.RtLat), o.formatCoord(bbox.RtLong), page.Num)
 	resp, err := o.request(ctx, url)
 	if err != nil {
 		return nil, errors.Wrap(err, "search url request")
@@ -82,19 +165,11 @@ func (o *Onliner) SearchArticles(ctx context.Context, page *model.Page) ([]*mode
 
 	ads := make([]*model.Ad, 0)
 	for _, onlinerAd := range onlinerResp.Apartments {
-		var house *string
-		address := onlinerAd.Location.Address
-		if address == "" {
-			address = onlinerAd.Location.UserAddress
-		}
-		var street *string
-		streetSplit, houseSplit := o.addressSplit(address)
-		if streetSplit != "" {
-			street = &streetSplit
-		}
-		if houseSplit != "" {
-			house = &houseSplit
+		addr := onlinerAd.Location.Address
+		if addr == "" {
+			addr = onlinerAd.Location.UserAddress
 		}
+		street, house := address.Split(ctx, addr, sec.region.Cities...).Ptrs()
 
 		var locLat, locLong *float64
 		var rooms, floor, floors *uint8
@@ -104,12 +179,20 @@ func (o *Onliner) SearchArticles(ctx context.Context, page *model.Page) ([]*mode
 		locLat = &lat
 		long := onlinerAd.Location.Longitude
 		locLong = &long
-		roomsPoint := uint8(onlinerAd.NumberOfRooms)
-		rooms = &roomsPoint
-		floorPoint := uint8(onlinerAd.Floor)
-		floor = &floorPoint
-		floorsPoint := uint8(onlinerAd.NumberOfFloors)
-		floors = &floorsPoint
+		if onlinerAd.NumberOfRooms > 0 {
+			roomsPoint := uint8(onlinerAd.NumberOfRooms)
+			rooms = &roomsPoint
+		} else if r := o.rentRooms(onlinerAd.RentType); r > 0 {
+			rooms = &r
+		}
+		if onlinerAd.Floor > 0 {
+			floorPoint := uint8(onlinerAd.Floor)
+			floor = &floorPoint
+		}
+		if onlinerAd.NumberOfFloors > 0 {
+			floorsPoint := uint8(onlinerAd.NumberOfFloors)
+			floors = &floorsPoint
+		}
 		if onlinerAd.Area.Total != nil {
 			m2Main = onlinerAd.Area.Total
 		}
@@ -130,122 +213,202 @@ func (o *Onliner) SearchArticles(ctx context.Context, page *model.Page) ([]*mode
 			}
 		}
 
+		var priceMonth *decimal.Decimal
+		var rentPeriod *model.RentPeriod
+		owner := onlinerAd.Seller.Type == sellerOwner
+		if sec.listing == model.ListingRent {
+			priceMonth, price = price, nil
+			rp := model.RentPeriodLong
+			rentPeriod = &rp
+			owner = onlinerAd.Contact.Owner
+		}
+
 		photos := make([]string, 0, 1)
 		if onlinerAd.Photo != "" {
 			photos = append(photos, onlinerAd.Photo)
 		}
 
-		extID := crc32.Checksum([]byte(onlinerAd.URL), o.crcTable)
 		created, errTime := datetime.NewDatetime(onlinerAd.CreatedAt.UTC())
 		if errTime != nil {
 			log.Warnf("error time convert %v to datetime: %s", onlinerAd.CreatedAt, errTime)
 		}
 
 		modelAd := &model.Ad{
-			ExtID:     extID,
-			Created:   created,
-			URL:       onlinerAd.URL,
-			Street:    street,
-			House:     house,
-			LocLat:    locLat,
-			LocLong:   locLong,
-			Price:     price,
-			Rooms:     rooms,
-			Floor:     floor,
-			Floors:    floors,
-			Photos:    photos,
-			M2Main:    m2Main,
-			M2Living:  m2Living,
-			M2Kitchen: m2Kitchen,
+			SourceID:   strings.TrimSuffix(sec.api, ".api") + "/" + strconv.Itoa(onlinerAd.ID),
+			ExtID:      model.LegacyExtID(onlinerAd.URL),
+			Created:    created,
+			URL:        onlinerAd.URL,
+			Street:     street,
+			House:      house,
+			LocLat:     locLat,
+			LocLong:    locLong,
+			Price:      price,
+			Rooms:      rooms,
+			Floor:      floor,
+			Floors:     floors,
+			Photos:     photos,
+			M2Main:     m2Main,
+			M2Living:   m2Living,
+			M2Kitchen:  m2Kitchen,
+			Listing:    sec.listing,
+			Property:   sec.property,
+			Region:     sec.region.Name,
+			PriceMonth: priceMonth,
+			RentPeriod: rentPeriod,
+			Owner:      &owner,
 		}
 		ads = append(ads, modelAd)
 	}
 
-	if page.Num == onlinerResp.Page.Last {
-		return ads, model.ErrLastPage
+	if page.Num >= onlinerResp.Page.Last {
+		if sectionID == len(sections)-1 {
+			return ads, model.ErrLastPage
+		}
+		onlinerPage.Section = sections[sectionID+1].key()
+		page.Num = 0
 	}
 
+	page.Next = onlinerPage
+
 	return ads, nil
 }
 
+// DownloadArticle sets description, photo gallery and attributes of detail page,
+// ad is returned as is with error when detail page is not available
 func (o *Onliner) DownloadArticle(ctx context.Context, modelAd *model.Ad) (*model.Ad, error) {
-	_ = ctx
-	return modelAd, nil
-}
+	// source id is section and id of apartment like pk/123
+	api, id, ok := strings.Cut(modelAd.SourceID, "/")
+	if !ok {
+		return modelAd, errors.Errorf("invalid apartment source id %q", modelAd.SourceID)
+	}
 
-func (o *Onliner) addressSplit(address string) (street, house string) {
-	street = address
-	addressSplit := strings.Split(address, ",")
-	addressParts := make([]string, 0, len(addressSplit))
-	for _, as := range addressSplit {
-		f := false
-		for _, del := range delCities {
-			if strings.Contains(as, del) {
-				f = true
-				break
-			}
-		}
-		if !f && as != "" {
-			addressParts = append(addressParts, strings.TrimSpace(as))
-		}
+	resp, err := o.request(ctx, fmt.Sprintf(apartmentURL, api+".api", id))
+	if err != nil {
+		return modelAd, errors.Wrap(err, "apartment url request")
 	}
 
-	if len(addressParts) > 1 {
-		last := addressParts[len(addressParts)-1]
-		if o.checkHasDigit(last) {
-			street = strings.Join(addressParts[0:len(addressParts)-1], ", ")
-			house = last
-		} else {
-			street = strings.Join(addressParts, ", ")
-		}
-	} else if len(addressParts) > 0 {
-		streetSplit := strings.Split(addressParts[0], ".")
-		if len(streetSplit) > 1 {
-			last := streetSplit[len(streetSplit)-1]
-			if o.checkHasDigit(last) {
-				street = strings.Join(streetSplit[0:len(streetSplit)-1], ". ")
-				house = last
-			} else {
-				street = addressParts[0]
-			}
-		} else {
-			streetSplit = strings.Split(addressParts[0], " ")
-			if len(streetSplit) > 1 {
-				last := streetSplit[len(streetSplit)-1]
-				if o.checkHasDigit(last) {
-					street = strings.Join(streetSplit[0:len(streetSplit)-1], " ")
-					house = last
-				} else {
-					street = addressParts[0]
-				}
-			} else if len(streetSplit) > 0 {
-				street = addressParts[0]
-			}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return modelAd, model.ErrTooManyRequests
+	}
+	if resp.StatusCode != http.StatusOK {
+		return modelAd, fmt.Errorf("apartment %s status %d: %w",
+			modelAd.SourceID, resp.StatusCode, model.ErrArticleStatus)
+	}
+
+	var apartment RespApartment
+	err = json.NewDecoder(resp.Body).Decode(&apartment)
+	if err != nil {
+		return modelAd, fmt.Errorf("apartment response decode %s: %w", o.GetCode(), err)
+	}
+
+	if description := strings.TrimSpace(apartment.Description); description != "" {
+		modelAd.Description = &description
+	}
+
+	photos := make([]string, 0, len(apartment.Photos))
+	for _, photo := range apartment.Photos {
+		if photo.URL != "" {
+			photos = append(photos, photo.URL)
 		}
 	}
+	if len(photos) > 0 {
+		modelAd.Photos = photos
+	}
+
+	var seller model.SellerType
+	switch {
+	case apartment.Seller.Type == sellerOwner:
+		seller = model.SellerOwner
+	case apartment.Seller.Type == sellerAgent:
+		seller = model.SellerAgency
+	case apartment.Seller.Type == sellerBuilder:
+		seller = model.SellerDeveloper
+	case apartment.Contact.Owner:
+		// seller of rent apartment is set by contact only
+		seller = model.SellerOwner
+	default:
+		seller = model.SellerAgency
+	}
+	modelAd.Seller = &seller
+	phoneHidden := len(apartment.Contact.Phones) == 0
+	modelAd.PhoneHidden = &phoneHidden
+
+	params := apartment.Parameters
+	modelAd.Material = o.label(wallingLabels, params.Walling)
+	modelAd.CeilingHeight = params.CeilingHeight
+	modelAd.Balcony = o.label(balconyLabels, params.Balcony)
+	modelAd.Renovation = o.label(renovationLabels, params.Renovation)
+	modelAd.Parking = o.label(parkingLabels, params.Parking)
+
+	return modelAd, nil
+}
+
+// label returns readable label of code of detail page
+func (o *Onliner) label(labels map[string]string, code string) *string {
+	if code == "" {
+		return nil
+	}
+	if l, ok := labels[code]; ok {
+		return &l
+	}
 
-	return strings.TrimSpace(street), strings.TrimSpace(house)
+	return &code
 }
 
-func (o *Onliner) checkHasDigit(str string) bool {
-	reg := regexp.MustCompile(`\d+`)
-	numbers := reg.FindAllString(str, -1)
+func (o *Onliner) formatCoord(coord float64) string {
+	return strconv.FormatFloat(coord, 'f', -1, 64)
+}
+
+// rentRooms converts rent type like "2_rooms" to rooms count
+func (o *Onliner) rentRooms(rentType string) uint8 {
+	roomsSplit := strings.Split(rentType, "_")
+	if len(roomsSplit) < 2 {
+		return 0
+	}
 
-	return len(numbers) > 0
+	rc, err := strconv.ParseUint(roomsSplit[0], 10, 8)
+	if err != nil {
+		return 0
+	}
+
+	return uint8(rc)
 }
 
 func (o *Onliner) request(ctx context.Context, url string) (*http.Response, error) {
-	var client http.Client
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json, text/plain, */*")
-	resp, err := client.Do(req)
+	resp, err := transport.Get(ctx).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error request body page: %w", err)
 	}
 
 	return resp, nil
 }
+
+// key returns key of section saved by checkpoint
+func (s section) key() string {
+	return search.Key(s.region, s.category)
+}
+
+func (o *Onliner) sections(ctx context.Context) []section {
+	return search.Sections(ctx, o.GetCode(), categories, func(region configs.Region, c category) section {
+		return section{region, c}
+	})
+}
+
+func (o *Onliner) getCurrentPage(page *model.Page) *Page {
+	onlinerPage := &Page{}
+	if op, ok := page.Next.(*Page); ok {
+		onlinerPage = op
+	}
+
+	return onlinerPage
+}
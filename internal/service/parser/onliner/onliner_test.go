@@ -0,0 +1,27 @@
+package onliner
+
+import (
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/replay/replaytest"
+	"github.com/sku4/ad-parser/model"
+)
+
+var testParser = configs.Parser{
+	Listings: []string{model.ListingSale.String(), model.ListingRent.String()},
+}
+
+func TestProfile(t *testing.T) {
+	replaytest.Profile{
+		Parser: testParser,
+		New: func() replaytest.Source {
+			return New()
+		},
+		SourceIDs: []replaytest.SourceID{
+			{URL: "https://r.onliner.by/pk/apartments/2001", ID: "pk/2001", OK: true},
+			{URL: "https://r.onliner.by/ak/apartments/2001", ID: "ak/2001", OK: true},
+			{URL: "https://r.onliner.by/pk/"},
+		},
+	}.Run(t)
+}
@@ -0,0 +1,34 @@
+package onliner
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+)
+
+type Page struct {
+	Section string `json:"section"` // key of section
+}
+
+// NextPage decodes pagination data saved by checkpoint of search, checkpoint of section
+// which is not searched any more is not resumed
+func (o *Onliner) NextPage(ctx context.Context, data []byte) (interface{}, error) {
+	onlinerPage := &Page{}
+	if err := json.Unmarshal(data, onlinerPage); err != nil {
+		return nil, errors.Wrap(err, "next page unmarshal")
+	}
+
+	if err := search.Resumed(o.sections(ctx), onlinerPage.Section, section.key); err != nil {
+		return nil, errors.Wrap(err, "next page")
+	}
+
+	return onlinerPage, nil
+}
+
+// LastPage returns page after the last section of search
+func (o *Onliner) LastPage() *model.Page {
+	return &model.Page{Num: 1, Next: &Page{Section: search.LastSection}}
+}
@@ -39,6 +39,10 @@ type Resp struct {
 		Seller struct {
 			Type string `json:"type"`
 		} `json:"seller"`
+		RentType string `json:"rent_type"`
+		Contact  struct {
+			Owner bool `json:"owner"`
+		} `json:"contact"`
 		CreatedAt     time.Time `json:"created_at"`
 		LastTimeUp    time.Time `json:"last_time_up"`
 		UpAvailableIn int       `json:"up_available_in"`
@@ -56,3 +60,27 @@ type Resp struct {
 		Last    int `json:"last"`
 	} `json:"page"`
 }
+
+// RespApartment is response of detail page of apartment
+type RespApartment struct {
+	ID          int    `json:"id"`
+	Description string `json:"description"`
+	Photos      []struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	} `json:"photos"`
+	Seller struct {
+		Type string `json:"type"`
+	} `json:"seller"`
+	Contact struct {
+		Owner  bool     `json:"owner"`
+		Phones []string `json:"phones"`
+	} `json:"contact"`
+	Parameters struct {
+		Walling       string   `json:"walling"`
+		CeilingHeight *float64 `json:"ceiling_height"`
+		Balcony       string   `json:"balcony"`
+		Renovation    string   `json:"renovation"`
+		Parking       string   `json:"parking"`
+	} `json:"parameters"`
+}
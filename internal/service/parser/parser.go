@@ -2,12 +2,19 @@ package parser
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/sku4/ad-parser/configs"
 	"github.com/sku4/ad-parser/internal/repository"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
+	"github.com/sku4/ad-parser/model"
 	"github.com/sku4/ad-parser/pkg/logger"
 )
 
@@ -18,6 +25,10 @@ type Service struct {
 	wg         *sync.WaitGroup
 	mu         sync.RWMutex
 	cacheClean *lru.Cache[uint16, time.Time]
+	statuses   map[string]*Status
+	stuckTime  time.Duration
+	muStreet   sync.Mutex
+	normalizer *address.Normalizer
 }
 
 func NewService(repos *repository.Repository) *Service {
@@ -31,50 +42,32 @@ func NewService(repos *repository.Repository) *Service {
 		repos:      repos,
 		wg:         &sync.WaitGroup{},
 		cacheClean: cacheClean,
+		statuses:   make(map[string]*Status, len(codeProfiles)),
 	}
 }
 
 func (s *Service) Run(ctx context.Context) (err error) {
 	log := logger.Get()
 	cfg := configs.Get(ctx)
+	ctx = s.prepare(ctx)
 
 	for _, code := range cfg.Profiles {
-		if _, ok := codeProfiles[code]; !ok {
+		ok, errEnabled := enabled(code)
+		if errEnabled != nil {
 			log.Errorf("Parser '%s' not found", code)
 			continue
 		}
+		if !ok {
+			log.Warnf("Parser '%s' is disabled", code)
+			continue
+		}
 
 		s.wg.Add(1)
 		go func(ctx context.Context, wg *sync.WaitGroup, code string) {
 			defer wg.Done()
 
 			for {
-				needClean := false
-				s.mu.RLock()
-				codeProfile := codeProfiles[code]
-				var cleanTime *time.Time
-				now := time.Now()
-				if t, ok := s.cacheClean.Get(codeProfile.GetID()); ok {
-					cleanTime = &t
-				}
-				if cleanTime == nil || cleanTime.Before(now.Add(-cfg.Parser.CleanTime)) {
-					s.cacheClean.Add(codeProfile.GetID(), now)
-					needClean = true
-				}
-				s.mu.RUnlock()
-
-				if needClean {
-					log.Infof("Parser '%s' is running with clean", code)
-				} else {
-					log.Infof("Parser '%s' is running", code)
-				}
-
-				profile := NewProfile(s.repos, codeProfiles[code], needClean)
-
-				if err = profile.Parse(ctx); err != nil {
-					log.Errorf("Parser '%s' not might parse: %s", code, err)
-				}
-				log.Infof("Parser '%s' was ends of work", code)
+				s.parse(s.addresses(ctx), code, CleanAuto)
 
 				timer := time.NewTimer(cfg.Parser.CheckTime)
 				select {
@@ -89,6 +82,188 @@ func (s *Service) Run(ctx context.Context) (err error) {
 	return nil
 }
 
+// RunOnce parses profiles exactly once, all configured profiles are parsed when codes are empty
+func (s *Service) RunOnce(ctx context.Context, codes []string, clean CleanMode) ([]*Summary, error) {
+	cfg := configs.Get(ctx)
+	ctx = s.addresses(s.prepare(ctx))
+
+	if len(codes) == 0 {
+		codes = cfg.Profiles
+	}
+	for _, code := range codes {
+		ok, errEnabled := enabled(code)
+		if errEnabled != nil {
+			return nil, errEnabled
+		}
+		if !ok {
+			return nil, fmt.Errorf("parser '%s': %w", code, model.ErrProfileDisabled)
+		}
+	}
+
+	summaries := make([]*Summary, len(codes))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(codes))
+	for i, code := range codes {
+		go func() {
+			defer wg.Done()
+			summaries[i] = s.parse(ctx, code, clean)
+		}()
+	}
+	wg.Wait()
+
+	return summaries, nil
+}
+
+// MigrateSourceIDs sets source id of ads saved before it by urls of ads
+func (s *Service) MigrateSourceIDs(ctx context.Context) (uint64, error) {
+	return s.repos.Ad.MigrateSourceIDs(ctx, SourceID)
+}
+
+// SourceID returns source id of ad of profile by url of ad
+func SourceID(profileID uint16, url string) (string, bool) {
+	for _, profile := range codeProfiles {
+		if profile.GetID() == profileID {
+			return profile.SourceID(url)
+		}
+	}
+
+	return "", false
+}
+
+// prepare sets http client to context
+func (s *Service) prepare(ctx context.Context) context.Context {
+	cfg := configs.Get(ctx)
+
+	s.mu.Lock()
+	s.stuckTime = cfg.Parser.StuckTime
+	s.mu.Unlock()
+
+	return transport.Set(ctx, transport.New(cfg.Parser.HTTP))
+}
+
+// addresses sets normalizer of addresses by street types of storage to context, street types
+// are loaded once, context is returned without normalizer when they fail to load, so addresses
+// keep street type words as they are written on site and types are loaded again by the next parse
+func (s *Service) addresses(ctx context.Context) context.Context {
+	if s.repos.Street == nil {
+		return ctx
+	}
+
+	s.muStreet.Lock()
+	defer s.muStreet.Unlock()
+
+	if s.normalizer == nil {
+		types, err := s.repos.Street.Types(ctx)
+		if err != nil {
+			logger.Get().Errorf("Street types are not loaded: %s", err)
+			return ctx
+		}
+		s.normalizer = address.NewNormalizer(slices.Collect(maps.Values(types)))
+	}
+
+	return address.Set(ctx, s.normalizer)
+}
+
+func (s *Service) parse(ctx context.Context, code string, clean CleanMode) *Summary {
+	log := logger.Get()
+
+	needClean := s.needClean(ctx, code, clean)
+	if needClean {
+		log.Infof("Parser '%s' is running with clean", code)
+	} else {
+		log.Infof("Parser '%s' is running", code)
+	}
+
+	profile := NewProfile(s.repos, codeProfiles[code], needClean)
+
+	s.setStart(code)
+	start := time.Now()
+	errParse := profile.Parse(ctx)
+	if errParse != nil {
+		log.Errorf("Parser '%s' not might parse: %s", code, errParse)
+	}
+	s.setFinish(code, errParse, profile.LastPage())
+	log.Infof("Parser '%s' was ends of work", code)
+
+	summary := profile.Summary()
+	summary.Duration = time.Since(start)
+	summary.Err = errParse
+
+	return summary
+}
+
+func (s *Service) needClean(ctx context.Context, code string, clean CleanMode) bool {
+	switch clean {
+	case CleanForce:
+		return true
+	case CleanSkip:
+		return false
+	default:
+	}
+
+	cfg := configs.Get(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profileID := codeProfiles[code].GetID()
+	now := time.Now()
+	if t, ok := s.cacheClean.Get(profileID); ok && !t.Before(now.Add(-cfg.Parser.CleanTime)) {
+		return false
+	}
+	s.cacheClean.Add(profileID, now)
+
+	return true
+}
+
+// Status returns last run status of every running profile
+func (s *Service) Status() []*Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]*Status, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		status := *st
+		status.Stuck = status.Running && s.stuckTime > 0 && now.Sub(*status.Start) > s.stuckTime
+		statuses = append(statuses, &status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Code < statuses[j].Code
+	})
+
+	return statuses
+}
+
+func (s *Service) setStart(code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	st, ok := s.statuses[code]
+	if !ok {
+		st = &Status{Code: code}
+		s.statuses[code] = st
+	}
+	st.Running = true
+	st.Start = &now
+}
+
+func (s *Service) setFinish(code string, err error, lastPage bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	st := s.statuses[code]
+	st.Running = false
+	st.Finish = &now
+	st.LastPage = lastPage
+	st.Error = ""
+	if err != nil {
+		st.Error = err.Error()
+	}
+}
+
 func (s *Service) Shutdown() error {
 	s.wg.Wait()
 
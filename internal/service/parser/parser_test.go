@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sku4/ad-parser/internal/repository"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/address/addresstest"
+	streetModel "github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+// streetRepo fails to load street types fails times
+type streetRepo struct {
+	fails int
+	calls int
+}
+
+func (r *streetRepo) Types(context.Context) (map[uint8]*streetModel.Type, error) {
+	r.calls++
+	if r.calls <= r.fails {
+		return nil, errors.New("street types error")
+	}
+
+	return map[uint8]*streetModel.Type{1: addresstest.Types[0]}, nil
+}
+
+func TestAddressesReload(t *testing.T) {
+	streets := &streetRepo{fails: 1}
+	s := &Service{repos: &repository.Repository{Ad: &batchRepo{}, Street: streets}}
+
+	ctx := s.addresses(context.Background())
+	if got := address.Split(ctx, "ул. Притыцкого, 62"); got.Type != nil {
+		t.Errorf("street without types %+v", got)
+	}
+
+	for range 2 {
+		ctx = s.addresses(context.Background())
+		if got := address.Split(ctx, "улица Притыцкого, 62"); got.Type == nil || got.StreetName() != "ул. Притыцкого" {
+			t.Errorf("street by types %+v", got)
+		}
+	}
+	if streets.calls != 2 {
+		t.Errorf("street types are loaded %d times, want 2", streets.calls)
+	}
+}
@@ -2,50 +2,79 @@ package parser
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/metrics"
 	"github.com/sku4/ad-parser/internal/repository"
+	"github.com/sku4/ad-parser/internal/service/parser/incremental"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
 	"github.com/sku4/ad-parser/model"
 	"github.com/sku4/ad-parser/pkg/logger"
 )
 
-type iProfile interface {
+// Source is parser of ads of registered profile
+type Source interface {
 	Auth(context.Context) error
 	SearchArticles(ctx context.Context, page *model.Page) (ads []*model.Ad, err error)
 	DownloadArticle(ctx context.Context, ad *model.Ad) (*model.Ad, error)
 	GetCode() string
 	GetID() uint16
+	// SourceID returns native id of ad in source by url of ad
+	SourceID(url string) (string, bool)
+	// NextPage decodes pagination data of profile saved by checkpoint of search,
+	// model.ErrSectionNotFound is returned for section which is not searched any more
+	NextPage(ctx context.Context, data []byte) (interface{}, error)
 }
 
 const (
-	chanBufferLen = 10000
-	timeSleep     = time.Second * 10
+	chanBufferLen        = 10000
+	timeSleep            = time.Second * 10
+	defaultSaveBatchSize = 100
+	defaultSaveBatchTime = time.Millisecond * 200
 )
 
+// found is ad found on searched page, detail page of unchanged ad is not downloaded again
+type found struct {
+	ad        *model.Ad
+	page      *searched
+	unchanged bool
+}
+
 type Profile struct {
-	iProfile
+	Source
 	repos           *repository.Repository
-	urlsChan        chan *model.Ad
-	adChan          chan *model.Ad
+	urlsChan        chan *found
+	adChan          chan *found
+	checkpoints     *checkpoints
 	rwMutex         *sync.RWMutex
 	tooManyReqLimit int
+	tooManyReqCount int
 	searchCount     int
 	saveCount       int
+	errorCount      int
 	checkLastPage   bool
 	needClean       bool
+	incremental     bool
+	// same keeps unchanged source ids of searched page found by checker of incremental search,
+	// so storage is queried once per page
+	same map[string]bool
 }
 
-func NewProfile(repos *repository.Repository, profile iProfile, needClean bool) *Profile {
+func NewProfile(repos *repository.Repository, profile Source, needClean bool) *Profile {
 	return &Profile{
-		repos:     repos,
-		iProfile:  profile,
-		urlsChan:  make(chan *model.Ad, chanBufferLen),
-		adChan:    make(chan *model.Ad, chanBufferLen),
-		rwMutex:   &sync.RWMutex{},
-		needClean: needClean,
+		repos:       repos,
+		Source:      profile,
+		urlsChan:    make(chan *found, chanBufferLen),
+		adChan:      make(chan *found, chanBufferLen),
+		checkpoints: newCheckpoints(repos, profile.GetID()),
+		rwMutex:     &sync.RWMutex{},
+		needClean:   needClean,
 	}
 }
 
@@ -54,17 +83,22 @@ func (p *Profile) Parse(ctx context.Context) (err error) {
 	cfg := configs.Get(ctx)
 
 	p.tooManyReqLimit = cfg.Parser.TooManyReqLimit
-	start := time.Now()
+	p.incremental = cfg.Parser.Incremental && !p.needClean && !cfg.Parser.DryRun
+	page, start := p.resume(ctx)
 
 	// auth
-	if err = p.iProfile.Auth(ctx); err != nil {
+	if err = p.Source.Auth(ctx); err != nil {
 		return model.ErrProfileNotMightAuth
 	}
 
-	// search new articles
+	// search new articles, full search is done on clean run only
+	searchCtx := ctx
+	if p.incremental {
+		searchCtx = incremental.Set(ctx, p.unchanged)
+	}
 	wg.Add(1)
 	go func() {
-		p.searchArticles(ctx, wg)
+		p.searchArticles(searchCtx, wg, page, start)
 	}()
 
 	// download articles
@@ -86,22 +120,97 @@ func (p *Profile) Parse(ctx context.Context) (err error) {
 	close(p.adChan)
 	wgs.Wait()
 
-	if p.needClean && p.checkLastPage && p.searchCount == p.saveCount && p.searchCount > 0 {
+	p.logRates(ctx)
+
+	if p.needClean && !cfg.Parser.DryRun && p.checkLastPage && p.searchCount == p.saveCount && p.searchCount > 0 {
 		p.cleanArticles(ctx, start)
 	}
 
+	if p.checkLastPage && p.searchCount > 0 {
+		metrics.LastSuccessRun.WithLabelValues(p.Source.GetCode()).SetToCurrentTime()
+	}
+
 	return nil
 }
 
-func (p *Profile) searchArticles(ctx context.Context, wg *sync.WaitGroup) {
+func (p *Profile) Summary() *Summary {
+	p.rwMutex.RLock()
+	defer p.rwMutex.RUnlock()
+
+	return &Summary{
+		Code:     p.Source.GetCode(),
+		Searched: p.searchCount,
+		Saved:    p.saveCount,
+		Errors:   p.errorCount,
+		LastPage: p.checkLastPage,
+	}
+}
+
+// LastPage reports whether the search reached the last page
+func (p *Profile) LastPage() bool {
+	p.rwMutex.RLock()
+	defer p.rwMutex.RUnlock()
+
+	return p.checkLastPage
+}
+
+// resume returns page and start time of search interrupted by the previous run,
+// search starts from the first page when there is no checkpoint, checkpoint
+// is saved by search of other mode, incremental or full one, or by search of section
+// which is not searched any more
+func (p *Profile) resume(ctx context.Context) (*model.Page, time.Time) {
+	log := logger.Get()
+	page, start := &model.Page{Num: 1}, time.Now()
+
+	cp, err := p.repos.Checkpoint.Load(ctx, p.Source.GetID())
+	if err != nil {
+		p.incErrors(1)
+		log.Errorf("Load checkpoint error: %s", err)
+		return page, start
+	}
+	if cp == nil {
+		return page, start
+	}
+	if cp.Incremental != p.incremental {
+		log.Infof("Parser '%s' does not resume search of other mode from page %d", p.Source.GetCode(), cp.Num)
+		return page, start
+	}
+
+	next, err := p.Source.NextPage(ctx, cp.Next)
+	if errors.Is(err, model.ErrSectionNotFound) {
+		log.Infof("Parser '%s' does not resume search from page %d: %s", p.Source.GetCode(), cp.Num, err)
+		return page, start
+	}
+	if err != nil {
+		p.incErrors(1)
+		log.Errorf("Load checkpoint error: %s", err)
+		return page, start
+	}
+	log.Infof("Parser '%s' resumes search from page %d started at %s",
+		p.Source.GetCode(), cp.Num, cp.Started.Format(time.DateTime))
+
+	return &model.Page{Num: cp.Num, Next: next}, cp.Started
+}
+
+// searchedPage adds searched page with count of its ads to checkpoints,
+// checkpoint is nil after the last page
+func (p *Profile) searchedPage(ctx context.Context, cp *model.Checkpoint, ads int) *searched {
+	page, err := p.checkpoints.add(ctx, cp, ads)
+	if err != nil {
+		p.incErrors(1)
+		logger.Get().Errorf("Checkpoint error: %s", err)
+	}
+
+	return page
+}
+
+func (p *Profile) searchArticles(ctx context.Context, wg *sync.WaitGroup, page *model.Page, start time.Time) {
 	defer wg.Done()
 	defer close(p.urlsChan)
 
 	log := logger.Get()
+	code := p.Source.GetCode()
 
-	page := &model.Page{
-		Num: 1,
-	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -109,33 +218,64 @@ func (p *Profile) searchArticles(ctx context.Context, wg *sync.WaitGroup) {
 		default:
 		}
 
-		p.rwMutex.RLock()
-		if p.tooManyReqLimit <= 0 {
-			p.rwMutex.RUnlock()
-			return
-		}
-		p.rwMutex.RUnlock()
-
-		urls, err := p.iProfile.SearchArticles(ctx, page)
+		timeSearch := time.Now()
+		p.same = nil
+		urls, err := p.Source.SearchArticles(ctx, page)
+		metrics.SearchDuration.WithLabelValues(code).Observe(time.Since(timeSearch).Seconds())
 		if err != nil && !errors.Is(err, model.ErrLastPage) && !errors.Is(err, model.ErrTooManyRequests) {
+			p.incErrors(1)
 			log.Errorf("Search articles page num %d error: %s", page.Num, err)
 			time.Sleep(timeSleep)
 		}
 
+		// checkpoint of search after the page is saved when ads of the page are saved
+		var tracked *searched
+		switch {
+		case err == nil:
+			page.Num++
+			cp, errCp := page.Checkpoint(p.Source.GetID(), start, p.incremental)
+			if errCp != nil {
+				p.incErrors(1)
+				log.Errorf("Checkpoint page num %d error: %s", page.Num, errCp)
+			}
+			if cp != nil {
+				tracked = p.searchedPage(ctx, cp, len(urls))
+			}
+		case errors.Is(err, model.ErrLastPage):
+			tracked = p.searchedPage(ctx, nil, len(urls))
+		}
+
+		// detail pages of saved unchanged ads are downloaded on clean run only
+		same := p.same
+		if same == nil && !p.needClean && len(urls) > 0 {
+			same = p.unchangedIDs(ctx, urls)
+		}
 		for _, url := range urls {
-			p.urlsChan <- url
+			p.urlsChan <- &found{ad: url, page: tracked, unchanged: same[url.SourceID]}
 		}
 
 		p.rwMutex.Lock()
 		p.searchCount += len(urls)
 		p.rwMutex.Unlock()
 
+		if err == nil || errors.Is(err, model.ErrLastPage) {
+			metrics.PagesSearched.WithLabelValues(code).Inc()
+		}
+		metrics.AdsFound.WithLabelValues(code).Add(float64(len(urls)))
+
+		// requests are slowed down by rate limiter of transport,
+		// search stops only when throttled too many times in a row
 		if errors.Is(err, model.ErrTooManyRequests) {
-			p.rwMutex.Lock()
-			p.tooManyReqLimit--
-			p.rwMutex.Unlock()
-			log.Warnf("Search articles too many requests: page num %d", page.Num)
-			time.Sleep(timeSleep)
+			metrics.TooManyRequests.WithLabelValues(code).Inc()
+			p.tooManyReqCount++
+			log.Warnf("Search articles too many requests: page num %d, rates %s",
+				page.Num, p.formatRates(ctx))
+			if p.tooManyReqCount >= p.tooManyReqLimit {
+				log.Errorf("Search articles stopped after %d too many requests in a row", p.tooManyReqCount)
+				return
+			}
+		} else {
+			p.tooManyReqCount = 0
 		}
 
 		if errors.Is(err, model.ErrLastPage) {
@@ -144,75 +284,214 @@ func (p *Profile) searchArticles(ctx context.Context, wg *sync.WaitGroup) {
 			p.rwMutex.Unlock()
 			break
 		}
+	}
+}
 
-		if err == nil {
-			page.Num++
+// unchanged reports whether all ads are saved already with the same prices
+// and they are not updated in source since they were saved
+func (p *Profile) unchanged(ctx context.Context, ads []*model.Ad) bool {
+	p.same = p.unchangedIDs(ctx, ads)
+	for _, ad := range ads {
+		if !p.same[ad.SourceID] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// unchangedIDs returns source ids of ads saved already with the same prices
+// which are not updated in source since they were saved
+func (p *Profile) unchangedIDs(ctx context.Context, ads []*model.Ad) map[string]bool {
+	log := logger.Get()
+
+	sourceIDs := make([]string, 0, len(ads))
+	for _, ad := range ads {
+		sourceIDs = append(sourceIDs, ad.SourceID)
+	}
+
+	stored, err := p.repos.Ad.Stored(ctx, sourceIDs, p.Source.GetID())
+	if err != nil {
+		log.Errorf("Search articles stored ads error: %s", err)
+		return nil
+	}
+
+	storedAds := make(map[string]*model.Ad, len(stored))
+	for _, ad := range stored {
+		storedAds[ad.SourceID] = ad
+	}
+
+	same := make(map[string]bool, len(ads))
+	for _, ad := range ads {
+		storedAd, ok := storedAds[ad.SourceID]
+		if !ok || !model.PriceEqual(storedAd.Price, ad.Price) || !model.PriceEqual(storedAd.PriceMonth, ad.PriceMonth) {
+			continue
 		}
+		if ad.SourceUpdated != nil && (storedAd.Updated == nil || ad.SourceUpdated.After(storedAd.Updated.ToTime())) {
+			continue
+		}
+		same[ad.SourceID] = true
 	}
+
+	return same
 }
 
 func (p *Profile) downloadArticles(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	log := logger.Get()
+	code := p.Source.GetCode()
 
-	for ad := range p.urlsChan {
+	for f := range p.urlsChan {
 		select {
 		case <-ctx.Done():
 			return
 		default:
 		}
 
-		p.rwMutex.RLock()
-		if p.tooManyReqLimit <= 0 {
-			p.rwMutex.RUnlock()
-			return
+		// saved photos and detail fields of unchanged ad are kept by storage
+		ad := f.ad
+		if f.unchanged {
+			p.adChan <- f
+			continue
 		}
-		p.rwMutex.RUnlock()
 
-		modelAd, err := p.iProfile.DownloadArticle(ctx, ad)
+		timeDownload := time.Now()
+		modelAd, err := p.Source.DownloadArticle(ctx, ad)
+		metrics.DownloadDuration.WithLabelValues(code).Observe(time.Since(timeDownload).Seconds())
 		if err != nil && !errors.Is(err, model.ErrTooManyRequests) {
+			metrics.DownloadErrors.WithLabelValues(code).Inc()
+			p.incErrors(1)
 			log.Errorf("Download article (%s) error: %s", ad.URL, err)
 		}
 
 		if errors.Is(err, model.ErrTooManyRequests) {
-			p.rwMutex.Lock()
-			p.tooManyReqLimit--
-			p.rwMutex.Unlock()
-			log.Warnf("Download article too many requests (%s)", ad.URL)
+			metrics.TooManyRequests.WithLabelValues(code).Inc()
+			log.Warnf("Download article too many requests (%s), rates %s", ad.URL, p.formatRates(ctx))
 		}
 
+		// ad is saved with search data when detail page fails, saved detail is kept then
 		if modelAd != nil {
-			p.adChan <- modelAd
+			modelAd.Detailed = err == nil
+			p.adChan <- &found{ad: modelAd, page: f.page}
 		}
 	}
 }
 
+// saveArticles gathers ads to batch until it is full or batch time is over
+// since the first ad of batch, then saves the whole batch
 func (p *Profile) saveArticles(ctx context.Context, wgs *sync.WaitGroup) {
 	defer wgs.Done()
+
+	cfg := configs.Get(ctx)
+	batchSize, batchTime := cfg.Parser.SaveBatchSize, cfg.Parser.SaveBatchTime
+	if batchSize <= 0 {
+		batchSize = defaultSaveBatchSize
+	}
+	if batchTime <= 0 {
+		batchTime = defaultSaveBatchTime
+	}
+
+	batch := make([]*found, 0, batchSize)
+	timer := time.NewTimer(batchTime)
+	timer.Stop()
+	defer timer.Stop()
+
+	flush := func() {
+		timer.Stop()
+		if len(batch) > 0 {
+			p.saveBatch(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case ad, ok := <-p.adChan:
+			if !ok {
+				flush()
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(batchTime)
+			}
+			batch = append(batch, ad)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// saveBatch saves batch of ads and advances checkpoint of search by saved ads
+func (p *Profile) saveBatch(ctx context.Context, batch []*found) {
 	log := logger.Get()
 
-	successCnt := 0
-	profileID := p.iProfile.GetID()
-	for ad := range p.adChan {
-		err := p.repos.Ad.Put(ctx, ad, profileID)
+	ads := make([]*model.Ad, 0, len(batch))
+	for _, f := range batch {
+		ads = append(ads, f.ad)
+	}
+
+	successCnt, errorCnt := 0, 0
+	code := p.Source.GetCode()
+	errs := p.repos.Ad.PutBatch(ctx, ads, p.Source.GetID())
+	for i, err := range errs {
 		if err != nil {
-			log.Errorf("Save articles error: %s", err)
+			errorCnt++
+			log.Errorf("Save article (%s) error: %s", ads[i].URL, err)
 		} else {
 			successCnt++
 		}
+		if errCp := p.checkpoints.saved(ctx, batch[i].page, err == nil); errCp != nil {
+			p.incErrors(1)
+			log.Errorf("Checkpoint error: %s", errCp)
+		}
 	}
+	metrics.SaveErrors.WithLabelValues(code).Add(float64(errorCnt))
+	metrics.AdsSaved.WithLabelValues(code).Add(float64(successCnt))
 
 	p.rwMutex.Lock()
 	p.saveCount += successCnt
+	p.errorCount += errorCnt
 	p.rwMutex.Unlock()
 }
 
 func (p *Profile) cleanArticles(ctx context.Context, timeStart time.Time) {
 	log := logger.Get()
 
-	err := p.repos.Ad.Clean(ctx, timeStart, p.iProfile.GetID())
+	cnt, err := p.repos.Ad.Clean(ctx, timeStart, p.Source.GetID())
 	if err != nil {
+		p.incErrors(1)
 		log.Errorf("Clean articles error: %s", err)
 	}
+	metrics.CleanedTuples.WithLabelValues(p.Source.GetCode()).Add(float64(cnt))
+}
+
+func (p *Profile) incErrors(cnt int) {
+	p.rwMutex.Lock()
+	p.errorCount += cnt
+	p.rwMutex.Unlock()
+}
+
+func (p *Profile) logRates(ctx context.Context) {
+	log := logger.Get()
+	log.Infof("Parser '%s' request rates %s", p.Source.GetCode(), p.formatRates(ctx))
+}
+
+func (p *Profile) formatRates(ctx context.Context) string {
+	rates := transport.Get(ctx).Rates()
+	hosts := make([]string, 0, len(rates))
+	for host := range rates {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	formatted := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		formatted = append(formatted, fmt.Sprintf("%s=%.2f rps", host, rates[host]))
+	}
+
+	return strings.Join(formatted, ", ")
 }
@@ -0,0 +1,476 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	dec "github.com/shopspring/decimal"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/repository"
+	memoryCheckpoint "github.com/sku4/ad-parser/internal/repository/memory/checkpoint"
+	"github.com/sku4/ad-parser/internal/service/parser/incremental"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
+	"github.com/sku4/ad-parser/model"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+var errSave = errors.New("save error")
+
+// batchRepo records sizes of batches and fails ads with odd ext id when failOdd is set
+type batchRepo struct {
+	mu      sync.Mutex
+	sizes   []int
+	failOdd bool
+	stored  []*model.Ad
+	// storedCalls counts queries of stored ads
+	storedCalls int
+}
+
+func (r *batchRepo) Put(ctx context.Context, ad *model.Ad, profileID uint16) error {
+	return r.PutBatch(ctx, []*model.Ad{ad}, profileID)[0]
+}
+
+func (r *batchRepo) PutBatch(_ context.Context, ads []*model.Ad, _ uint16) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sizes = append(r.sizes, len(ads))
+	errs := make([]error, len(ads))
+	for i, ad := range ads {
+		if r.failOdd && ad.ExtID%2 == 1 {
+			errs[i] = errSave
+		}
+	}
+
+	return errs
+}
+
+func (r *batchRepo) Clean(context.Context, time.Time, uint16) (uint64, error) {
+	return 0, nil
+}
+
+func (r *batchRepo) Stored(context.Context, []string, uint16) ([]*model.Ad, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.storedCalls++
+
+	return r.stored, nil
+}
+
+func (r *batchRepo) MigrateSourceIDs(context.Context, model.SourceIDFunc) (uint64, error) {
+	return 0, nil
+}
+
+type testProfile struct{}
+
+func (testProfile) Auth(context.Context) error { return nil }
+func (testProfile) SearchArticles(context.Context, *model.Page) ([]*model.Ad, error) {
+	return nil, model.ErrLastPage
+}
+func (testProfile) DownloadArticle(_ context.Context, ad *model.Ad) (*model.Ad, error) {
+	return ad, nil
+}
+func (testProfile) SourceID(string) (string, bool) { return "", false }
+func (testProfile) GetCode() string                { return "test" }
+func (testProfile) GetID() uint16                  { return 1 }
+func (testProfile) NextPage(context.Context, []byte) (interface{}, error) {
+	return nil, nil
+}
+
+type pagedNext struct {
+	Cursor string `json:"cursor"`
+}
+
+// pagedProfile returns one ad on each of pages, it is throttled on page throttled
+type pagedProfile struct {
+	testProfile
+	pages     int
+	throttled int
+}
+
+func (pp pagedProfile) SearchArticles(_ context.Context, page *model.Page) ([]*model.Ad, error) {
+	if page.Num == pp.throttled {
+		return nil, model.ErrTooManyRequests
+	}
+	if next, ok := page.Next.(*pagedNext); ok && next.Cursor != fmt.Sprintf("c%d", page.Num) {
+		return nil, fmt.Errorf("unexpected cursor %s of page %d", next.Cursor, page.Num)
+	}
+	page.Next = &pagedNext{Cursor: fmt.Sprintf("c%d", page.Num+1)}
+	ads := []*model.Ad{{SourceID: strconv.Itoa(page.Num)}}
+	if page.Num == pp.pages {
+		return ads, model.ErrLastPage
+	}
+
+	return ads, nil
+}
+
+func (pagedProfile) NextPage(_ context.Context, data []byte) (interface{}, error) {
+	next := &pagedNext{}
+	err := json.Unmarshal(data, next)
+
+	return next, err
+}
+
+func TestSaveArticlesBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		ads       int
+		batchSize int
+		failOdd   bool
+		sizes     []int
+		saved     int
+		errors    int
+	}{
+		{"full batches", 6, 3, false, []int{3, 3}, 6, 0},
+		{"rest is flushed on close", 7, 3, false, []int{3, 3, 1}, 7, 0},
+		{"errors per ad", 4, 10, true, []int{4}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &batchRepo{failOdd: tt.failOdd}
+			p := NewProfile(&repository.Repository{Ad: repo}, testProfile{}, false)
+			ctx := configs.Set(context.Background(), &configs.Config{
+				Parser: configs.Parser{
+					SaveBatchSize: tt.batchSize,
+					SaveBatchTime: time.Hour,
+				},
+			})
+
+			for i := 0; i < tt.ads; i++ {
+				p.adChan <- &found{ad: &model.Ad{ExtID: uint32(i)}}
+			}
+			close(p.adChan)
+
+			wgs := &sync.WaitGroup{}
+			wgs.Add(1)
+			p.saveArticles(ctx, wgs)
+
+			if !reflect.DeepEqual(repo.sizes, tt.sizes) {
+				t.Errorf("batch sizes %v, want %v", repo.sizes, tt.sizes)
+			}
+			summary := p.Summary()
+			if summary.Saved != tt.saved || summary.Errors != tt.errors {
+				t.Errorf("saved %d with %d errors, want %d with %d errors",
+					summary.Saved, summary.Errors, tt.saved, tt.errors)
+			}
+		})
+	}
+}
+
+func TestSaveArticlesBatchTime(t *testing.T) {
+	repo := &batchRepo{}
+	p := NewProfile(&repository.Repository{Ad: repo}, testProfile{}, false)
+	ctx := configs.Set(context.Background(), &configs.Config{
+		Parser: configs.Parser{
+			SaveBatchSize: 100,
+			SaveBatchTime: time.Millisecond * 10,
+		},
+	})
+
+	wgs := &sync.WaitGroup{}
+	wgs.Add(1)
+	go p.saveArticles(ctx, wgs)
+
+	p.adChan <- &found{ad: &model.Ad{ExtID: 1}}
+	deadline := time.Now().Add(time.Second)
+	for {
+		repo.mu.Lock()
+		flushed := len(repo.sizes) > 0
+		repo.mu.Unlock()
+		if flushed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("batch is not saved after batch time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(p.adChan)
+	wgs.Wait()
+	if !reflect.DeepEqual(repo.sizes, []int{1}) {
+		t.Errorf("batch sizes %v, want [1]", repo.sizes)
+	}
+}
+
+func TestUnchanged(t *testing.T) {
+	saved := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	updated, _ := datetime.NewDatetime(saved)
+	before, after := saved.Add(-time.Hour), saved.Add(time.Hour)
+	price := func(p int64) *decimal.Decimal {
+		return decimal.NewDecimal(dec.NewFromInt(p))
+	}
+	stored := []*model.Ad{
+		{SourceID: "1", Updated: updated, Price: price(100)},
+		{SourceID: "2", Updated: updated, PriceMonth: price(300)},
+	}
+
+	tests := []struct {
+		name      string
+		ads       []*model.Ad
+		unchanged bool
+	}{
+		{"all known", []*model.Ad{
+			{SourceID: "1", Price: price(100), SourceUpdated: &before},
+			{SourceID: "2", PriceMonth: price(300)},
+		}, true},
+		{"unknown ad", []*model.Ad{
+			{SourceID: "1", Price: price(100)},
+			{SourceID: "3", Price: price(100)},
+		}, false},
+		{"price changed", []*model.Ad{{SourceID: "1", Price: price(90)}}, false},
+		{"price removed", []*model.Ad{{SourceID: "1"}}, false},
+		{"updated after save", []*model.Ad{{SourceID: "1", Price: price(100), SourceUpdated: &after}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProfile(&repository.Repository{Ad: &batchRepo{stored: stored}}, testProfile{}, false)
+			if got := p.unchanged(context.Background(), tt.ads); got != tt.unchanged {
+				t.Errorf("unchanged %v, want %v", got, tt.unchanged)
+			}
+		})
+	}
+}
+
+// incrementalProfile stops search on page of known ads like profile sorted by update time
+type incrementalProfile struct {
+	testProfile
+}
+
+func (incrementalProfile) SearchArticles(ctx context.Context, _ *model.Page) ([]*model.Ad, error) {
+	ads := []*model.Ad{{SourceID: "1"}, {SourceID: "2"}}
+	if incremental.Unchanged(ctx, ads) {
+		return ads, model.ErrLastPage
+	}
+
+	return ads, nil
+}
+
+func TestSearchArticlesIncremental(t *testing.T) {
+	repo := &batchRepo{stored: []*model.Ad{{SourceID: "1"}, {SourceID: "2"}}}
+	p := NewProfile(&repository.Repository{Ad: repo}, incrementalProfile{}, false)
+	p.incremental = true
+	ctx := incremental.Set(context.Background(), p.unchanged)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go p.searchArticles(ctx, wg, &model.Page{}, time.Now())
+	unchanged := 0
+	for f := range p.urlsChan {
+		if f.unchanged {
+			unchanged++
+		}
+	}
+	wg.Wait()
+
+	if !p.LastPage() || unchanged != 2 {
+		t.Fatalf("search is not stopped on page of known ads, unchanged %d", unchanged)
+	}
+	if repo.storedCalls != 1 {
+		t.Errorf("stored ads are queried %d times for page, want 1", repo.storedCalls)
+	}
+}
+
+func TestSearchArticlesResume(t *testing.T) {
+	repos := &repository.Repository{Ad: &batchRepo{}, Checkpoint: memoryCheckpoint.NewCheckpoint()}
+	ctx := transport.Set(context.Background(), transport.New(configs.HTTP{}))
+	search := func(profile pagedProfile) (*Profile, time.Time) {
+		p := NewProfile(repos, profile, false)
+		p.tooManyReqLimit = 1
+		page, start := p.resume(ctx)
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go p.searchArticles(ctx, wg, page, start)
+		for f := range p.urlsChan {
+			p.saveBatch(ctx, []*found{f})
+		}
+		wg.Wait()
+
+		return p, start
+	}
+
+	p, start := search(pagedProfile{pages: 5, throttled: 3})
+	if p.LastPage() || p.Summary().Searched != 2 {
+		t.Fatalf("expected search stopped on page 3, summary %+v", p.Summary())
+	}
+	cp, err := repos.Checkpoint.Load(ctx, 1)
+	if err != nil || cp == nil {
+		t.Fatalf("checkpoint is not saved: %v", err)
+	}
+	if cp.Num != 3 || string(cp.Next) != `{"cursor":"c3"}` || !cp.Started.Equal(start) {
+		t.Fatalf("unexpected checkpoint %+v", cp)
+	}
+
+	p, resumed := search(pagedProfile{pages: 5})
+	if !p.LastPage() || p.Summary().Searched != 3 || p.Summary().Errors != 0 {
+		t.Fatalf("expected search resumed from page 3, summary %+v", p.Summary())
+	}
+	if !resumed.Equal(start) {
+		t.Errorf("resumed search started at %s, want %s", resumed, start)
+	}
+	if cp, err = repos.Checkpoint.Load(ctx, 1); err != nil || cp != nil {
+		t.Errorf("checkpoint is not reset after the last page: %+v, %v", cp, err)
+	}
+}
+
+// detailProfile fails detail page of ad with source id fail
+type detailProfile struct {
+	testProfile
+}
+
+func (detailProfile) DownloadArticle(_ context.Context, ad *model.Ad) (*model.Ad, error) {
+	if ad.SourceID == "fail" {
+		return ad, errSave
+	}
+	description := "detail"
+	ad.Description = &description
+
+	return ad, nil
+}
+
+func TestDownloadArticlesDetail(t *testing.T) {
+	p := NewProfile(&repository.Repository{Ad: &batchRepo{}}, detailProfile{}, false)
+	ctx := transport.Set(context.Background(), transport.New(configs.HTTP{}))
+	p.urlsChan <- &found{ad: &model.Ad{SourceID: "new"}}
+	p.urlsChan <- &found{ad: &model.Ad{SourceID: "fail"}}
+	p.urlsChan <- &found{ad: &model.Ad{SourceID: "same"}, unchanged: true}
+	close(p.urlsChan)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	p.downloadArticles(ctx, wg)
+	close(p.adChan)
+
+	tests := []struct {
+		sourceID    string
+		detailed    bool
+		description bool
+	}{
+		{"new", true, true},
+		{"fail", false, false},
+		{"same", false, false},
+	}
+	for _, tt := range tests {
+		ad := (<-p.adChan).ad
+		if ad.SourceID != tt.sourceID || ad.Detailed != tt.detailed || (ad.Description != nil) != tt.description {
+			t.Errorf("ad %s detailed %v with description %v, want %s detailed %v with description %v",
+				ad.SourceID, ad.Detailed, ad.Description != nil, tt.sourceID, tt.detailed, tt.description)
+		}
+	}
+	if p.Summary().Errors != 1 {
+		t.Errorf("errors %d, want 1", p.Summary().Errors)
+	}
+}
+
+func TestCheckpointsAdvance(t *testing.T) {
+	ctx := context.Background()
+	repos := &repository.Repository{Checkpoint: memoryCheckpoint.NewCheckpoint()}
+	c := newCheckpoints(repos, 1)
+	load := func() int {
+		cp, err := repos.Checkpoint.Load(ctx, 1)
+		if err != nil {
+			t.Fatalf("load checkpoint: %s", err)
+		}
+		if cp == nil {
+			return 0
+		}
+
+		return cp.Num
+	}
+	add := func(num, ads int) *searched {
+		page, err := c.add(ctx, &model.Checkpoint{Profile: 1, Num: num}, ads)
+		if err != nil {
+			t.Fatalf("add page %d: %s", num, err)
+		}
+
+		return page
+	}
+	saved := func(page *searched, ok bool) {
+		if err := c.saved(ctx, page, ok); err != nil {
+			t.Fatalf("saved: %s", err)
+		}
+	}
+
+	page1, page2 := add(2, 2), add(3, 1)
+	if load() != 0 {
+		t.Fatal("checkpoint is advanced before ads are saved")
+	}
+	saved(page2, true)
+	saved(page1, true)
+	if load() != 0 {
+		t.Fatalf("checkpoint %d is advanced while ad of the first page is not saved", load())
+	}
+	saved(page1, true)
+	if load() != 3 {
+		t.Fatalf("checkpoint %d, want 3 when ads of both pages are saved", load())
+	}
+	add(4, 0)
+	if load() != 4 {
+		t.Fatalf("checkpoint %d, want 4 after page without ads", load())
+	}
+	page4 := add(5, 2)
+	saved(page4, false)
+	saved(page4, true)
+	if load() != 4 {
+		t.Fatalf("checkpoint %d is advanced after ad is not saved, want 4", load())
+	}
+}
+
+func TestResumeMode(t *testing.T) {
+	ctx := context.Background()
+	repos := &repository.Repository{Ad: &batchRepo{}, Checkpoint: memoryCheckpoint.NewCheckpoint()}
+	started := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := repos.Checkpoint.Save(ctx, &model.Checkpoint{
+		Profile: 1, Num: 3, Next: []byte(`{"cursor":"c3"}`), Started: started, Incremental: true,
+	})
+	if err != nil {
+		t.Fatalf("save checkpoint: %s", err)
+	}
+
+	for _, incremental := range []bool{false, true} {
+		p := NewProfile(repos, pagedProfile{}, false)
+		p.incremental = incremental
+		page, start := p.resume(ctx)
+		if resumed := page.Num == 3 && start.Equal(started); resumed != incremental {
+			t.Errorf("search of incremental %v resumed %v from checkpoint of incremental search",
+				incremental, resumed)
+		}
+	}
+}
+
+// removedProfile is paged profile which does not search section of checkpoint any more
+type removedProfile struct {
+	pagedProfile
+}
+
+func (removedProfile) NextPage(context.Context, []byte) (interface{}, error) {
+	return nil, model.ErrSectionNotFound
+}
+
+func TestResumeRemovedSection(t *testing.T) {
+	ctx := context.Background()
+	repos := &repository.Repository{Ad: &batchRepo{}, Checkpoint: memoryCheckpoint.NewCheckpoint()}
+	err := repos.Checkpoint.Save(ctx, &model.Checkpoint{
+		Profile: 1, Num: 3, Next: []byte(`{"cursor":"c3"}`), Started: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("save checkpoint: %s", err)
+	}
+
+	p := NewProfile(repos, removedProfile{}, false)
+	if page, _ := p.resume(ctx); page.Num != 1 || page.Next != nil {
+		t.Errorf("search is resumed from page %+v of removed section", page)
+	}
+	if p.Summary().Errors != 0 {
+		t.Errorf("removed section is counted as error")
+	}
+}
@@ -1,5 +1,34 @@
 package realt
 
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/model"
+)
+
 type Page struct {
-	CategoryID int
+	Section string `json:"section"` // key of section
+}
+
+// NextPage decodes pagination data saved by checkpoint of search, checkpoint of section
+// which is not searched any more is not resumed
+func (r *Realt) NextPage(ctx context.Context, data []byte) (interface{}, error) {
+	realtPage := &Page{}
+	if err := json.Unmarshal(data, realtPage); err != nil {
+		return nil, errors.Wrap(err, "next page unmarshal")
+	}
+
+	if err := search.Resumed(r.sections(ctx), realtPage.Section, section.key); err != nil {
+		return nil, errors.Wrap(err, "next page")
+	}
+
+	return realtPage, nil
+}
+
+// LastPage returns page after the last section of search
+func (r *Realt) LastPage() *model.Page {
+	return &model.Page{Num: 1, Next: &Page{Section: search.LastSection}}
 }
@@ -5,29 +5,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"hash/crc32"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/incremental"
+	"github.com/sku4/ad-parser/internal/service/parser/search"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
 	"github.com/sku4/ad-parser/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
 	"github.com/sku4/ad-parser/pkg/logger"
 	"github.com/tarantool/go-tarantool/v2/datetime"
 	"github.com/tarantool/go-tarantool/v2/decimal"
 )
 
 type Realt struct {
-	crcTable *crc32.Table
+	id uint16
+}
+
+func init() {
+	parser.MustRegister(New())
 }
 
 func New() *Realt {
 	return &Realt{
-		crcTable: crc32.MakeTable(crc32.IEEE),
+		id: profile.Realt.ID,
 	}
 }
 
 const (
-	profileID    = 3
 	graphQLURL   = "https://realt.by/bff/graphql"
 	graphQLQuery = "query searchObjects($data: GetObjectsByAddressInput!) {\n  " +
 		"searchObjects(data: $data) {\n    body {\n      results {\n        location\n        " +
@@ -40,32 +51,81 @@ const (
 		"pagination {\n        page\n        pageSize\n        totalCount\n      }\n      " +
 		"}\n    ...StatusAndErrors\n  }\n}\n\nfragment StatusAndErrors on INullResponse {\n  success\n  errors {\n    " +
 		"code\n    title\n    message\n    field\n  }\n}"
-	graphQLPageSize = 1000
-	topLeftLat      = 53.822171699379794
-	topLeftLong     = 27.36090453127423
-	bottomRightLat  = 53.97823316350124
-	bottomRightLong = 27.731935461117997
-	urlFlatMask     = "https://realt.by/sale-flats/object/%d/"
-	urlCottagesMask = "https://realt.by/sale-cottages/object/%d/"
-	adsCap          = 360
-	toiletO         = 0
-	toilet1         = 1
-	toilet2         = 2
+	graphQLObjectQuery = "query object($data: GetObjectInput!) {\n  object(data: $data) {\n    body {\n      " +
+		"code\n      description\n      images\n      agencyName\n      isDeveloper\n      " +
+		"contactPhones\n      wallMaterial\n      ceilingHeight\n      balconyType\n      " +
+		"repairState\n      parking\n    }\n    ...StatusAndErrors\n  }\n}\n\n" +
+		"fragment StatusAndErrors on INullResponse {\n  success\n  errors {\n    " +
+		"code\n    title\n    message\n    field\n  }\n}"
+	graphQLPageSize   = 1000
+	urlFlatMask       = "https://realt.by/sale-flats/object/%d/"
+	urlCottagesMask   = "https://realt.by/sale-cottages/object/%d/"
+	urlRentFlatMask   = "https://realt.by/rent-flat-for-long/object/%d/"
+	urlOfficeMask     = "https://realt.by/sale-offices/object/%d/"
+	urlRentOfficeMask = "https://realt.by/rent-offices/object/%d/"
+	urlShopMask       = "https://realt.by/sale-shops/object/%d/"
+	urlRentShopMask   = "https://realt.by/rent-shops/object/%d/"
+	urlGarageMask     = "https://realt.by/sale-garages/object/%d/"
+	urlPlotMask       = "https://realt.by/sale-plots/object/%d/"
+	m2Are             = 100
+	adsCap            = 360
+	toiletO           = 0
+	toilet1           = 1
+	toilet2           = 2
 )
 
+type category struct {
+	id       int
+	urlMask  string
+	listing  model.Listing
+	property model.PropertyType
+}
+
+type section struct {
+	region configs.Region
+	category
+}
+
+func (c category) Listing() model.Listing {
+	return c.listing
+}
+
+func (c category) Property() model.PropertyType {
+	return c.property
+}
+
 var (
-	categories = []string{
-		"5",
-		"11",
+	// sourceIDRe matches code of object in url like https://realt.by/sale-flats/object/4001/
+	sourceIDRe = regexp.MustCompile(`realt\.by/[^/?#]+/object/(\d+)`)
+	categories = []category{
+		{5, urlFlatMask, model.ListingSale, model.PropertyFlat},
+		{11, urlCottagesMask, model.ListingSale, model.PropertyHouse},
+		{2, urlRentFlatMask, model.ListingRent, model.PropertyFlat},
+		{20, urlOfficeMask, model.ListingSale, model.PropertyOffice},
+		{21, urlRentOfficeMask, model.ListingRent, model.PropertyOffice},
+		{22, urlShopMask, model.ListingSale, model.PropertyRetail},
+		{23, urlRentShopMask, model.ListingRent, model.PropertyRetail},
+		{24, urlGarageMask, model.ListingSale, model.PropertyGarage},
+		{15, urlPlotMask, model.ListingSale, model.PropertyLand},
 	}
 )
 
 func (r *Realt) GetCode() string {
-	return "realt"
+	return profile.Realt.Code
 }
 
 func (r *Realt) GetID() uint16 {
-	return profileID
+	return r.id
+}
+
+// SourceID returns id of ad by its url, it is used to migrate ads saved before source id
+func (r *Realt) SourceID(url string) (string, bool) {
+	m := sourceIDRe.FindStringSubmatch(url)
+	if m == nil {
+		return "", false
+	}
+
+	return m[1], true
 }
 
 func (r *Realt) Auth(ctx context.Context) error {
@@ -78,10 +138,13 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 	log := logger.Get()
 
 	realtPage := r.getCurrentPage(page)
-	categoryID, err := strconv.Atoi(categories[realtPage.CategoryID])
-	if err != nil {
-		return nil, errors.Wrap(err, "category id convert to int")
+	sections := r.sections(ctx)
+	sectionID, ok := search.Index(sections, realtPage.Section, section.key)
+	if !ok {
+		return nil, model.ErrLastPage
 	}
+	sec := sections[sectionID]
+	realtPage.Section = sec.key()
 
 	realtReq := &ReqGraphQL{
 		OperationName: "searchObjects",
@@ -93,11 +156,11 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 					PageSize: graphQLPageSize,
 				},
 				Where: ReqWhere{
-					Category: categoryID,
+					Category: sec.id,
 					Geo: ReqGeo{
 						Bbox: [][]float64{
-							{topLeftLong, topLeftLat},
-							{bottomRightLong, bottomRightLat},
+							{sec.region.Bbox.LbLong, sec.region.Bbox.LbLat},
+							{sec.region.Bbox.RtLong, sec.region.Bbox.RtLat},
 						},
 					},
 				},
@@ -134,27 +197,22 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 
 	ads := make([]*model.Ad, 0, adsCap)
 	for _, realtAd := range realtResp.Data.SearchObjects.Body.Results {
-		var house *string
-		var street *string
-		h := ""
+		streetName, h := "", ""
 		if realtAd.HouseNumber != nil && *realtAd.HouseNumber > 0 {
 			h = strconv.Itoa(*realtAd.HouseNumber)
 		}
 		if realtAd.BuildingNumber != nil && *realtAd.BuildingNumber != "" {
 			h = fmt.Sprintf("%s/%s", h, *realtAd.BuildingNumber)
 		}
-		if h != "" {
-			house = &h
-		}
-		if realtAd.StreetName != nil && *realtAd.StreetName != "" {
-			sp := *realtAd.StreetName
-			street = &sp
+		if realtAd.StreetName != nil {
+			streetName = *realtAd.StreetName
 		}
+		street, house := address.Normalize(ctx, streetName, h).Ptrs()
 
 		var locLat, locLong *float64
 		var rooms, floor, floors *uint8
 		var year *uint16
-		var m2Main, m2Living, m2Kitchen *float64
+		var m2Main, m2Living, m2Kitchen, m2Land *float64
 		var bathroom *string
 
 		if len(realtAd.Location) > 1 {
@@ -199,6 +257,12 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 			m2Kitchen = &ak
 		}
 
+		// area of land is in ares
+		if realtAd.AreaLand != nil && *realtAd.AreaLand > 0 {
+			al := *realtAd.AreaLand * m2Are
+			m2Land = &al
+		}
+
 		if realtAd.Toilet != nil {
 			switch *realtAd.Toilet {
 			case toiletO:
@@ -213,7 +277,7 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 			}
 		}
 
-		var price *decimal.Decimal
+		var price, priceMonth *decimal.Decimal
 		var errPrice error
 		if realtAd.Price != nil && *realtAd.Price > 0 {
 			fs := strconv.FormatFloat(*realtAd.Price, 'f', 2, 64)
@@ -223,45 +287,64 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 			}
 		}
 
+		var rentPeriod *model.RentPeriod
+		if sec.listing == model.ListingRent {
+			priceMonth, price = price, nil
+			rp := model.RentPeriodLong
+			rentPeriod = &rp
+		}
+
+		var agency *string
+		owner := realtAd.AgencyName == ""
+		if !owner {
+			an := realtAd.AgencyName
+			agency = &an
+		}
+
 		photos := make([]string, 0, len(realtAd.Images))
 		photos = append(photos, realtAd.Images...)
 		if len(photos) > 0 {
 			photos = photos[0:1]
 		}
 
-		link := ""
-		switch categories[realtPage.CategoryID] {
-		case "11":
-			link = fmt.Sprintf(urlCottagesMask, realtAd.Code)
-		default:
-			link = fmt.Sprintf(urlFlatMask, realtAd.Code)
-		}
-
-		extID := crc32.Checksum([]byte(link), r.crcTable)
+		link := fmt.Sprintf(sec.urlMask, realtAd.Code)
 
 		created, errTime := datetime.NewDatetime(realtAd.CreatedAt.UTC())
 		if errTime != nil {
 			log.Warnf("error time convert %v to datetime: %s", realtAd.CreatedAt.UTC(), errTime)
 		}
 
+		sourceUpdated := realtAd.UpdatedAt.UTC()
+
 		modelAd := &model.Ad{
-			ExtID:     extID,
-			Created:   created,
-			URL:       link,
-			Street:    street,
-			House:     house,
-			LocLat:    locLat,
-			LocLong:   locLong,
-			Price:     price,
-			Rooms:     rooms,
-			Floor:     floor,
-			Floors:    floors,
-			Year:      year,
-			Photos:    photos,
-			M2Main:    m2Main,
-			M2Living:  m2Living,
-			M2Kitchen: m2Kitchen,
-			Bathroom:  bathroom,
+			SourceID:   strconv.Itoa(realtAd.Code),
+			ExtID:      model.LegacyExtID(link),
+			Created:    created,
+			URL:        link,
+			Street:     street,
+			House:      house,
+			LocLat:     locLat,
+			LocLong:    locLong,
+			Price:      price,
+			Rooms:      rooms,
+			Floor:      floor,
+			Floors:     floors,
+			Year:       year,
+			Photos:     photos,
+			M2Main:     m2Main,
+			M2Living:   m2Living,
+			M2Kitchen:  m2Kitchen,
+			M2Land:     m2Land,
+			Bathroom:   bathroom,
+			Listing:    sec.listing,
+			Property:   sec.property,
+			Region:     sec.region.Name,
+			PriceMonth: priceMonth,
+			RentPeriod: rentPeriod,
+			Owner:      &owner,
+			Agency:     agency,
+
+			SourceUpdated: &sourceUpdated,
 		}
 		ads = append(ads, modelAd)
 	}
@@ -271,11 +354,17 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 	if pagination.PageSize > 0 {
 		pageCount = (pagination.TotalCount / pagination.PageSize) + 1
 	}
-	if pagination.PageSize == 0 || page.Num == pageCount || len(realtResp.Data.SearchObjects.Body.Results) == 0 {
-		if realtPage.CategoryID == len(categories)-1 {
+	// ads are sorted by update time, so the rest of section is known when the whole page is known
+	unchanged := incremental.Unchanged(ctx, ads)
+	if unchanged {
+		log.Infof("Search section %s of %s stopped on page %d of known ads", sec.key(), r.GetCode(), page.Num)
+	}
+	if unchanged || pagination.PageSize == 0 || page.Num == pageCount ||
+		len(realtResp.Data.SearchObjects.Body.Results) == 0 {
+		if sectionID == len(sections)-1 {
 			return ads, model.ErrLastPage
 		}
-		realtPage.CategoryID++
+		realtPage.Section = sections[sectionID+1].key()
 		page.Num = 0
 	}
 
@@ -284,13 +373,82 @@ func (r *Realt) SearchArticles(ctx context.Context, page *model.Page) ([]*model.
 	return ads, nil
 }
 
+// DownloadArticle sets description, photo gallery and attributes of detail page,
+// ad is returned as is with error when detail page is not available
 func (r *Realt) DownloadArticle(ctx context.Context, modelAd *model.Ad) (*model.Ad, error) {
-	_ = ctx
+	code, err := strconv.Atoi(modelAd.SourceID)
+	if err != nil {
+		return modelAd, errors.Wrap(err, "object code")
+	}
+
+	realtReqData, err := json.Marshal(&ReqObjectGraphQL{
+		OperationName: "object",
+		Query:         graphQLObjectQuery,
+		Variables: ReqObjectVariables{
+			Data: ReqObjectData{
+				Code: code,
+			},
+		},
+	})
+	if err != nil {
+		return modelAd, errors.Wrap(err, "object marshal")
+	}
+
+	resp, err := r.request(ctx, graphQLURL, realtReqData)
+	if err != nil {
+		return modelAd, errors.Wrap(err, "object request")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return modelAd, model.ErrTooManyRequests
+	}
+	if resp.StatusCode != http.StatusOK {
+		return modelAd, fmt.Errorf("object %d status %d: %w", code, resp.StatusCode, model.ErrArticleStatus)
+	}
+
+	var realtResp RespObjectGraphQL
+	err = json.NewDecoder(resp.Body).Decode(&realtResp)
+	if err != nil {
+		return modelAd, fmt.Errorf("object response decode %s: %w", r.GetCode(), err)
+	}
+
+	// removed object is returned without body
+	object := realtResp.Data.Object.Body
+	if object == nil {
+		return modelAd, fmt.Errorf("object %d without body: %w", code, model.ErrArticleStatus)
+	}
+
+	if description := strings.TrimSpace(object.Description); description != "" {
+		modelAd.Description = &description
+	}
+	if len(object.Images) > 0 {
+		modelAd.Photos = object.Images
+	}
+
+	seller := model.SellerOwner
+	if object.IsDeveloper {
+		seller = model.SellerDeveloper
+	} else if object.AgencyName != "" {
+		seller = model.SellerAgency
+	}
+	modelAd.Seller = &seller
+	phoneHidden := len(object.ContactPhones) == 0
+	modelAd.PhoneHidden = &phoneHidden
+
+	modelAd.Material = object.WallMaterial
+	modelAd.CeilingHeight = object.CeilingHeight
+	modelAd.Balcony = object.BalconyType
+	modelAd.Renovation = object.RepairState
+	modelAd.Parking = object.Parking
+
 	return modelAd, nil
 }
 
 func (r *Realt) request(ctx context.Context, url string, jsonBody []byte) (*http.Response, error) {
-	var client http.Client
 	bodyReader := bytes.NewReader(jsonBody)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bodyReader)
 	if err != nil {
@@ -299,9 +457,7 @@ func (r *Realt) request(ctx context.Context, url string, jsonBody []byte) (*http
 
 	req.Header.Set("Accept", "application/json, text/plain, */*")
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) "+
-		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Safari/537.36")
-	resp, err := client.Do(req)
+	resp, err := transport.Get(ctx).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error request body page: %w", err)
 	}
@@ -309,6 +465,17 @@ func (r *Realt) request(ctx context.Context, url string, jsonBody []byte) (*http
 	return resp, nil
 }
 
+// key returns key of section saved by checkpoint
+func (s section) key() string {
+	return search.Key(s.region, s.category)
+}
+
+func (r *Realt) sections(ctx context.Context) []section {
+	return search.Sections(ctx, r.GetCode(), categories, func(region configs.Region, c category) section {
+		return section{region, c}
+	})
+}
+
 func (r *Realt) getCurrentPage(page *model.Page) *Page {
 	realtPage := &Page{}
 	if kp, ok := page.Next.(*Page); ok {
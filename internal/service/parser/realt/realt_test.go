@@ -0,0 +1,58 @@
+package realt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/incremental"
+	"github.com/sku4/ad-parser/internal/service/parser/replay"
+	"github.com/sku4/ad-parser/internal/service/parser/replay/replaytest"
+	"github.com/sku4/ad-parser/model"
+)
+
+var testParser = configs.Parser{
+	Listings: []string{model.ListingSale.String(), model.ListingRent.String()},
+	PropertyTypes: []string{
+		model.PropertyFlat.String(), model.PropertyHouse.String(), model.PropertyOffice.String(),
+		model.PropertyRetail.String(), model.PropertyGarage.String(), model.PropertyLand.String(),
+	},
+}
+
+func testContext() context.Context {
+	return replaytest.Context(replaytest.FixturesDir, testParser)
+}
+
+func TestProfile(t *testing.T) {
+	replaytest.Profile{
+		Parser: testParser,
+		New: func() replaytest.Source {
+			return New()
+		},
+		Removed: &model.Ad{SourceID: "9999", URL: "https://realt.by/sale-flats/object/9999/"},
+		SourceIDs: []replaytest.SourceID{
+			{URL: "https://realt.by/sale-flats/object/4001/", ID: "4001", OK: true},
+			{URL: "https://realt.by/rent-flat-for-long/object/4001", ID: "4001", OK: true},
+			{URL: "https://realt.by/sale/flats/"},
+		},
+	}.Run(t)
+}
+
+func TestSearchArticlesIncremental(t *testing.T) {
+	ctx := incremental.Set(testContext(), func(context.Context, []*model.Ad) bool {
+		return true
+	})
+
+	pages, err := replay.Search(ctx, New(), replaytest.MaxPages)
+	if err != nil {
+		t.Fatalf("search articles: %s", err)
+	}
+	if sections := len(New().sections(ctx)); len(pages) != sections {
+		t.Fatalf("expected %d pages, one per section, got %d", sections, len(pages))
+	}
+	for _, page := range pages {
+		if page.Num != 1 {
+			t.Fatalf("expected the first page of section only, got page %d", page.Num)
+		}
+	}
+}
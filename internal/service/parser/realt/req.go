@@ -37,3 +37,17 @@ type ReqSort struct {
 	By    string `json:"by"`
 	Order string `json:"order"`
 }
+
+type ReqObjectGraphQL struct {
+	OperationName string             `json:"operationName"`
+	Query         string             `json:"query"`
+	Variables     ReqObjectVariables `json:"variables"`
+}
+
+type ReqObjectVariables struct {
+	Data ReqObjectData `json:"data"`
+}
+
+type ReqObjectData struct {
+	Code int `json:"code"`
+}
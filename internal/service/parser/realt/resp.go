@@ -61,7 +61,7 @@ type RespGraphQL struct {
 					AreaKitchen       *float64     `json:"areaKitchen"`
 					AreaMax           *interface{} `json:"areaMax"`
 					AreaMin           *interface{} `json:"areaMin"`
-					AreaLand          *interface{} `json:"areaLand"`
+					AreaLand          *float64     `json:"areaLand"`
 					ObjectType        interface{}  `json:"objectType"`
 					Code              int          `json:"code"`
 					StateRegionName   string       `json:"stateRegionName"`
@@ -109,3 +109,26 @@ type RespGraphQL struct {
 		} `json:"searchObjects"`
 	} `json:"data"`
 }
+
+// RespObjectGraphQL is response of detail page of object
+type RespObjectGraphQL struct {
+	Data struct {
+		Object struct {
+			Body *struct {
+				Code          int      `json:"code"`
+				Description   string   `json:"description"`
+				Images        []string `json:"images"`
+				AgencyName    string   `json:"agencyName"`
+				IsDeveloper   bool     `json:"isDeveloper"`
+				ContactPhones []string `json:"contactPhones"`
+				WallMaterial  *string  `json:"wallMaterial"`
+				CeilingHeight *float64 `json:"ceilingHeight"`
+				BalconyType   *string  `json:"balconyType"`
+				RepairState   *string  `json:"repairState"`
+				Parking       *string  `json:"parking"`
+			} `json:"body"`
+			Success bool          `json:"success"`
+			Errors  []interface{} `json:"errors"`
+		} `json:"object"`
+	} `json:"data"`
+}
@@ -0,0 +1,235 @@
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	keyLength = 16
+	filePerm  = 0o600
+	dirPerm   = 0o750
+)
+
+var (
+	ErrFixtureNotFound = errors.New("fixture not found")
+)
+
+type Mode int
+
+const (
+	// ModeReplay serves responses from fixtures only, requests never leave the process
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real site and saves responses to fixtures
+	ModeRecord
+)
+
+// Transport is http round tripper which records responses to fixture files
+// and replays them offline, fixture is chosen by method, url and body of request
+type Transport struct {
+	dir  string
+	mode Mode
+	next http.RoundTripper
+	mu   sync.Mutex
+}
+
+func New(dir string, mode Mode, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Transport{
+		dir:  dir,
+		mode: mode,
+		next: next,
+	}
+}
+
+// Fixture is recorded pair of request and response, bodies in json format
+// are saved as is to keep fixtures readable and editable
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+type FixtureRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"`
+}
+
+// FixtureResponse keeps text body in utf-8, text of page in other charset
+// is encoded back to the charset on replay
+type FixtureResponse struct {
+	Status  int             `json:"status"`
+	Header  http.Header     `json:"header,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+	Text    string          `json:"text,omitempty"`
+	Charset string          `json:"charset,omitempty"`
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	path := filepath.Join(t.dir, Key(req.Method, req.URL.String(), reqBody)+".json")
+	if t.mode == ModeRecord {
+		return t.record(req, reqBody, path)
+	}
+
+	return t.replay(req, path)
+}
+
+// Key returns fixture name of request
+func Key(method, url string, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, method+" "+url+"\n")
+	_, _ = h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))[:keyLength]
+}
+
+func (t *Transport) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s (%s)", ErrFixtureNotFound, req.Method, req.URL, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err = json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("error decode fixture %s: %w", path, err)
+	}
+
+	body := []byte(fixture.Response.Text)
+	if fixture.Response.Charset != "" {
+		enc, errEnc := htmlindex.Get(fixture.Response.Charset)
+		if errEnc != nil {
+			return nil, fmt.Errorf("error charset of fixture %s: %w", path, errEnc)
+		}
+		if body, err = enc.NewEncoder().Bytes(body); err != nil {
+			return nil, fmt.Errorf("error encode fixture text %s: %w", path, err)
+		}
+	}
+	if len(fixture.Response.Body) > 0 {
+		// json body is indented in fixture file
+		var compact bytes.Buffer
+		if err = json.Compact(&compact, fixture.Response.Body); err != nil {
+			return nil, fmt.Errorf("error compact fixture body %s: %w", path, err)
+		}
+		body = compact.Bytes()
+	}
+
+	header := fixture.Response.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.Status, http.StatusText(fixture.Response.Status)),
+		StatusCode:    fixture.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) record(req *http.Request, reqBody []byte, path string) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fixture := Fixture{
+		Request: FixtureRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+		},
+		Response: FixtureResponse{
+			Status: resp.StatusCode,
+			Header: http.Header{},
+		},
+	}
+	fixture.Request.Body, fixture.Request.Text = splitBody(reqBody)
+	fixture.Response.Body, fixture.Response.Text = splitBody(respBody)
+	if !utf8.ValidString(fixture.Response.Text) {
+		enc, name, _ := charset.DetermineEncoding(respBody, resp.Header.Get("Content-Type"))
+		text, errDecode := enc.NewDecoder().Bytes(respBody)
+		if errDecode != nil {
+			return nil, fmt.Errorf("error decode response body: %w", errDecode)
+		}
+		fixture.Response.Text, fixture.Response.Charset = string(text), name
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		fixture.Response.Header.Set("Content-Type", ct)
+	}
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		fixture.Response.Header.Set("Retry-After", ra)
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encode fixture: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err = os.MkdirAll(t.dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("error create fixtures dir: %w", err)
+	}
+	if err = os.WriteFile(path, append(data, '\n'), filePerm); err != nil {
+		return nil, fmt.Errorf("error write fixture: %w", err)
+	}
+
+	return resp, nil
+}
+
+// splitBody returns body as json when it is valid json object or array, otherwise as text
+func splitBody(body []byte) (json.RawMessage, string) {
+	trimmed := strings.TrimSpace(string(body))
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid(body) {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, body); err == nil {
+			return compact.Bytes(), ""
+		}
+	}
+
+	return nil, string(body)
+}
@@ -0,0 +1,130 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestTransportRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"query":"`+r.URL.RawQuery+`","body":`+string(body)+`}`)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		want   string
+	}{
+		{"get", http.MethodGet, srv.URL + "/search?page=1", "", `{"query":"page=1","body":}`},
+		{"post", http.MethodPost, srv.URL + "/graphql", `{"page":2}`, `{"query":"","body":{"page":2}}`},
+	}
+
+	recorder := &http.Client{Transport: New(dir, ModeRecord, nil)}
+	player := &http.Client{Transport: New(dir, ModeReplay, nil)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := do(t, recorder, tt.method, tt.url, tt.body)
+			if recorded != tt.want {
+				t.Fatalf("recorded body %q, want %q", recorded, tt.want)
+			}
+
+			replayed := do(t, player, tt.method, tt.url, tt.body)
+			if replayed != tt.want {
+				t.Fatalf("replayed body %q, want %q", replayed, tt.want)
+			}
+		})
+	}
+
+	srv.Close()
+	replayed := do(t, player, http.MethodGet, srv.URL+"/search?page=1", "")
+	if replayed != tests[0].want {
+		t.Fatalf("replayed body after server stop %q, want %q", replayed, tests[0].want)
+	}
+}
+
+func TestTransportRecordReplayCharset(t *testing.T) {
+	dir := t.TempDir()
+	page, err := charmap.Windows1251.NewEncoder().String("<p>Квартира</p>")
+	if err != nil {
+		t.Fatalf("encode page: %s", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+		_, _ = io.WriteString(w, page)
+	}))
+	defer srv.Close()
+
+	recorder := &http.Client{Transport: New(dir, ModeRecord, nil)}
+	player := &http.Client{Transport: New(dir, ModeReplay, nil)}
+	if recorded := do(t, recorder, http.MethodGet, srv.URL, ""); recorded != page {
+		t.Fatalf("recorded body %q, want %q", recorded, page)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, Key(http.MethodGet, srv.URL, nil)+".json"))
+	if err != nil {
+		t.Fatalf("read fixture: %s", err)
+	}
+	var fixture Fixture
+	if err = json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("decode fixture: %s", err)
+	}
+	if fixture.Response.Text != "<p>Квартира</p>" || fixture.Response.Charset != "windows-1251" {
+		t.Fatalf("fixture text %q in charset %q, want utf-8 text", fixture.Response.Text, fixture.Response.Charset)
+	}
+
+	if replayed := do(t, player, http.MethodGet, srv.URL, ""); replayed != page {
+		t.Fatalf("replayed body %q, want %q", replayed, page)
+	}
+}
+
+func TestTransportReplayNotFound(t *testing.T) {
+	player := &http.Client{Transport: New(t.TempDir(), ModeReplay, nil)}
+
+	_, err := player.Get("https://example.com/unknown")
+	if !errors.Is(err, ErrFixtureNotFound) {
+		t.Fatalf("expected fixture not found error, got %v", err)
+	}
+}
+
+func do(t *testing.T, c *http.Client, method, url, body string) string {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("create request: %s", err)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %s", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response: %s", err)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,197 @@
+package replaytest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/address"
+	"github.com/sku4/ad-parser/internal/service/parser/address/addresstest"
+	"github.com/sku4/ad-parser/internal/service/parser/replay"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
+	"github.com/sku4/ad-parser/model"
+)
+
+const (
+	// FixturesDir is dir of fixtures of profile test
+	FixturesDir = "testdata/fixtures"
+	// MaxPages is limit of pages of search in tests
+	MaxPages       = 20
+	goldenSearch   = "testdata/search.golden.json"
+	goldenDownload = "testdata/download.golden.json"
+	testRPS        = 1000
+	filePerm       = 0o600
+	dirPerm        = 0o750
+)
+
+// flags of tests which use replaytest package
+var (
+	record = flag.Bool("record", false, "record fixtures from real sites instead of replay")
+	update = flag.Bool("update", false, "update golden files by current result")
+)
+
+// Context returns context with http client replaying fixtures from dir, normalizer of street
+// types seeded to storages and parser config, fixtures are recorded from real sites when test
+// is run with -record flag
+func Context(dir string, parser configs.Parser) context.Context {
+	mode := replay.ModeReplay
+	if *record {
+		mode = replay.ModeRecord
+	}
+
+	cfg := configs.HTTP{
+		RateLimit: configs.RateLimit{
+			RPS:   testRPS,
+			Burst: testRPS,
+		},
+	}
+	if mode == replay.ModeRecord {
+		cfg = configs.HTTP{}
+	}
+
+	ctx := address.Set(context.Background(), address.NewNormalizer(addresstest.Types))
+	ctx = configs.Set(ctx, &configs.Config{Parser: parser})
+
+	return transport.Set(ctx, transport.NewWithTransport(cfg, replay.New(dir, mode, nil)))
+}
+
+// Source is profile tested by Profile
+type Source interface {
+	replay.Downloader
+	SourceID(url string) (string, bool)
+	// LastPage returns page after the last section of search
+	LastPage() *model.Page
+}
+
+// SourceID is url of ad with its expected source id
+type SourceID struct {
+	URL string
+	ID  string
+	OK  bool
+}
+
+// Profile is test of profile by fixtures of FixturesDir, search results and downloaded ads
+// are compared with golden files testdata/search.golden.json and testdata/download.golden.json
+type Profile struct {
+	Parser configs.Parser
+	New    func() Source
+	// Removed is ad which detail page is not found, it must be returned as is
+	Removed *model.Ad
+	// SourceIDs are urls of ads with expected source ids
+	SourceIDs []SourceID
+}
+
+// Run runs search, search after the last section, download and source id tests of profile
+func (p Profile) Run(t *testing.T) {
+	t.Helper()
+
+	t.Run("SearchArticles", func(t *testing.T) {
+		pages, err := replay.Search(Context(FixturesDir, p.Parser), p.New(), MaxPages)
+		if err != nil {
+			t.Fatalf("search articles: %s", err)
+		}
+
+		Golden(t, goldenSearch, pages)
+	})
+
+	t.Run("SearchArticlesAfterLastSection", func(t *testing.T) {
+		source := p.New()
+		ads, err := source.SearchArticles(Context(FixturesDir, p.Parser), source.LastPage())
+		if !errors.Is(err, model.ErrLastPage) {
+			t.Fatalf("expected last page error, got %v", err)
+		}
+		if len(ads) != 0 {
+			t.Fatalf("expected no ads, got %d", len(ads))
+		}
+	})
+
+	t.Run("DownloadArticle", func(t *testing.T) {
+		ads, err := replay.Download(Context(FixturesDir, p.Parser), p.New(), MaxPages)
+		if err != nil {
+			t.Fatalf("download articles: %s", err)
+		}
+
+		Golden(t, goldenDownload, ads)
+	})
+
+	if p.Removed != nil {
+		t.Run("DownloadArticleRemoved", func(t *testing.T) {
+			ad := *p.Removed
+			got, err := p.New().DownloadArticle(Context(FixturesDir, p.Parser), &ad)
+			if !errors.Is(err, model.ErrArticleStatus) {
+				t.Fatalf("expected article status error, got %v", err)
+			}
+			if got != &ad || got.Description != nil {
+				t.Fatalf("ad is changed by removed ad: %+v", got)
+			}
+		})
+	}
+
+	t.Run("SourceID", func(t *testing.T) {
+		for _, tt := range p.SourceIDs {
+			got, ok := p.New().SourceID(tt.URL)
+			if got != tt.ID || ok != tt.OK {
+				t.Errorf("source id of %s: %q %v, want %q %v", tt.URL, got, ok, tt.ID, tt.OK)
+			}
+		}
+	})
+}
+
+// Golden compares got in json format with golden file,
+// golden file is rewritten when test is run with -update flag
+func Golden(t testing.TB, path string, got interface{}) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal result: %s", err)
+	}
+	data = append(data, '\n')
+
+	if *update {
+		if err = os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+			t.Fatalf("create golden dir: %s", err)
+		}
+		if err = os.WriteFile(path, data, filePerm); err != nil {
+			t.Fatalf("write golden file: %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		t.Fatalf("read golden file, run with -update to create it: %s", err)
+	}
+
+	if !bytes.Equal(want, data) {
+		t.Errorf("result differs from golden file %s, run with -update to accept it\n%s",
+			path, diff(want, data))
+	}
+}
+
+// diff returns first differing line of want and got
+func diff(want, got []byte) string {
+	wantLines := bytes.Split(want, []byte("\n"))
+	gotLines := bytes.Split(got, []byte("\n"))
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g []byte
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if !bytes.Equal(w, g) {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+
+	return ""
+}
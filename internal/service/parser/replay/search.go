@@ -0,0 +1,104 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sku4/ad-parser/model"
+)
+
+// Searcher is profile which search is walked through by Search
+type Searcher interface {
+	SearchArticles(ctx context.Context, page *model.Page) ([]*model.Ad, error)
+}
+
+// Downloader is profile which ads are downloaded by Download
+type Downloader interface {
+	Searcher
+	DownloadArticle(ctx context.Context, ad *model.Ad) (*model.Ad, error)
+}
+
+// Page is result of one search request in golden file
+type Page struct {
+	Num  int               `json:"num"`
+	Next interface{}       `json:"next"`
+	Last bool              `json:"last"`
+	Ads  []*model.AdRecord `json:"ads"`
+}
+
+// Search walks through pages the same way as parser does until model.ErrLastPage
+// is returned, error is returned when the last page is not reached in maxPages
+func Search(ctx context.Context, s Searcher, maxPages int) ([]*Page, error) {
+	pages := make([]*Page, 0)
+	page := &model.Page{
+		Num: 1,
+	}
+	for i := 0; i < maxPages; i++ {
+		num := page.Num
+		ads, err := s.SearchArticles(ctx, page)
+		last := errors.Is(err, model.ErrLastPage)
+		if err != nil && !last {
+			return pages, fmt.Errorf("search page %d: %w", num, err)
+		}
+
+		// next page is marshaled now because profiles change it in place
+		next, errNext := json.Marshal(page.Next)
+		if errNext != nil {
+			return pages, fmt.Errorf("marshal next page %d: %w", num, errNext)
+		}
+
+		records := make([]*model.AdRecord, 0, len(ads))
+		for _, ad := range ads {
+			records = append(records, ad.Record())
+		}
+		pages = append(pages, &Page{
+			Num:  num,
+			Next: json.RawMessage(next),
+			Last: last,
+			Ads:  records,
+		})
+
+		if last {
+			return pages, nil
+		}
+		page.Num++
+	}
+
+	return pages, fmt.Errorf("last page is not reached after %d pages", maxPages)
+}
+
+// Download searches ads the same way as Search and downloads detail page of each found ad
+func Download(ctx context.Context, d Downloader, maxPages int) ([]*model.AdRecord, error) {
+	ads := make([]*model.Ad, 0)
+	page := &model.Page{
+		Num: 1,
+	}
+	for i := 0; ; i++ {
+		if i == maxPages {
+			return nil, fmt.Errorf("last page is not reached after %d pages", maxPages)
+		}
+
+		found, err := d.SearchArticles(ctx, page)
+		ads = append(ads, found...)
+		if errors.Is(err, model.ErrLastPage) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("search page %d: %w", page.Num, err)
+		}
+		page.Num++
+	}
+
+	records := make([]*model.AdRecord, 0, len(ads))
+	for _, ad := range ads {
+		downloaded, err := d.DownloadArticle(ctx, ad)
+		if err != nil {
+			return nil, fmt.Errorf("download article %s: %w", ad.URL, err)
+		}
+		records = append(records, downloaded.Record())
+	}
+
+	return records, nil
+}
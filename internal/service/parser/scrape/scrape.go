@@ -0,0 +1,167 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/internal/service/parser/transport"
+	"github.com/sku4/ad-parser/model"
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	// numberRe matches number with spaces between groups of thousands and comma or dot before fraction
+	numberRe = regexp.MustCompile(`(?:\d{1,3}(?:[ \x{00a0}]\d{3})+|\d+)(?:[.,]\d+)?`)
+	spacesRe = regexp.MustCompile(`[\s\x{00a0}]+`)
+)
+
+// Document is parsed html page, relative urls of page are resolved by url of page
+// or by base element of page
+type Document struct {
+	*goquery.Document
+	base *url.URL
+}
+
+// Fetch requests page by http client of context and parses it in encoding of page
+func Fetch(ctx context.Context, pageURL string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error create request: %w", err)
+	}
+
+	resp, err := transport.Get(ctx).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error request page: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, model.ErrTooManyRequests
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page %s status %d: %w", pageURL, resp.StatusCode, model.ErrArticleStatus)
+	}
+
+	return Parse(resp.Body, resp.Header.Get("Content-Type"), pageURL)
+}
+
+// Parse parses html page in encoding declared by content type or by meta element of page,
+// windows-1251 pages are decoded to utf-8
+func Parse(r io.Reader, contentType, pageURL string) (*Document, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "page url parse")
+	}
+
+	utf8Reader, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "page charset")
+	}
+
+	doc, err := goquery.NewDocumentFromReader(utf8Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "page parse")
+	}
+
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if baseRef, errBase := base.Parse(strings.TrimSpace(href)); errBase == nil {
+			base = baseRef
+		}
+	}
+
+	return &Document{
+		Document: doc,
+		base:     base,
+	}, nil
+}
+
+// Resolve returns absolute url of reference found on page
+func (d *Document) Resolve(ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false
+	}
+	u, err := d.base.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+// URL returns absolute url of attribute of the first element of selection
+func (d *Document) URL(s *goquery.Selection, attr string) (string, bool) {
+	ref, ok := s.First().Attr(attr)
+	if !ok {
+		return "", false
+	}
+
+	return d.Resolve(ref)
+}
+
+// Text returns text of selection with collapsed spaces
+func Text(s *goquery.Selection) string {
+	return strings.TrimSpace(spacesRe.ReplaceAllString(s.Text(), " "))
+}
+
+// Find returns text of the first element matched by selector in selection
+func Find(s *goquery.Selection, selector string) string {
+	return Text(s.Find(selector).First())
+}
+
+// Numbers returns all numbers of text like "65 000 $" or "54,2 / 30,1 м²"
+func Numbers(text string) []float64 {
+	matches := numberRe.FindAllString(text, -1)
+	numbers := make([]float64, 0, len(matches))
+	for _, m := range matches {
+		m = strings.ReplaceAll(spacesRe.ReplaceAllString(strings.TrimSpace(m), ""), ",", ".")
+		if n, err := strconv.ParseFloat(m, 64); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+
+	return numbers
+}
+
+// Number returns the first number of text
+func Number(text string) (float64, bool) {
+	numbers := Numbers(text)
+	if len(numbers) == 0 {
+		return 0, false
+	}
+
+	return numbers[0], true
+}
+
+// Values returns values of rows of table or list of parameters by their labels
+func Values(s *goquery.Selection, rowSelector, labelSelector, valueSelector string) map[string]string {
+	values := make(map[string]string)
+	s.Find(rowSelector).Each(func(_ int, row *goquery.Selection) {
+		if l := Find(row, labelSelector); l != "" {
+			values[strings.TrimSuffix(l, ":")] = Find(row, valueSelector)
+		}
+	})
+
+	return values
+}
+
+// Value returns value of parameter by label, nil is returned for missed or empty value
+func Value(values map[string]string, label string) *string {
+	value, ok := values[label]
+	if !ok || value == "" {
+		return nil
+	}
+
+	return &value
+}
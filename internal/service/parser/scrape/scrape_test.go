@@ -0,0 +1,110 @@
+package scrape
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseCharset(t *testing.T) {
+	page := `<html><head><meta charset="windows-1251"></head><body><h1>Квартира в Минске</h1></body></html>`
+	encoded, err := charmap.Windows1251.NewEncoder().String(page)
+	if err != nil {
+		t.Fatalf("encode page: %s", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{"charset of header", "text/html; charset=windows-1251"},
+		{"charset of meta", "text/html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, errParse := Parse(bytes.NewReader([]byte(encoded)), tt.contentType, "https://example.com/")
+			if errParse != nil {
+				t.Fatalf("parse: %s", errParse)
+			}
+			if got := Find(doc.Selection, "h1"); got != "Квартира в Минске" {
+				t.Errorf("text %q, want decoded text", got)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	page := `<a href="/flat/1/">1</a><a href="2/">2</a><img src="//img.example.com/1.jpg"><a>empty</a>`
+	tests := []struct {
+		name    string
+		page    string
+		pageURL string
+		want    []string
+	}{
+		{"page url", page, "https://example.com/sale/?page=2",
+			[]string{"https://example.com/flat/1/", "https://example.com/sale/2/"}},
+		{"base element", `<base href="https://www.example.com/rent/">` + page, "https://example.com/sale/",
+			[]string{"https://www.example.com/flat/1/", "https://www.example.com/rent/2/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(bytes.NewReader([]byte(tt.page)), "text/html; charset=utf-8", tt.pageURL)
+			if err != nil {
+				t.Fatalf("parse: %s", err)
+			}
+			got := make([]string, 0)
+			doc.Find("a").Each(func(_ int, s *goquery.Selection) {
+				if u, ok := doc.URL(s, "href"); ok {
+					got = append(got, u)
+				}
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("urls %v, want %v", got, tt.want)
+			}
+			if u, _ := doc.URL(doc.Find("img"), "src"); u != "https://img.example.com/1.jpg" {
+				t.Errorf("protocol relative url %s", u)
+			}
+		})
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want []float64
+	}{
+		{"65 000 $", []float64{65000}},
+		{"1 250 000 р.", []float64{1250000}},
+		{"54,2 / 30,1 / 8.5 м²", []float64{54.2, 30.1, 8.5}},
+		{"этаж 3 9", []float64{3, 9}},
+		{"цена договорная", []float64{}},
+	}
+	for _, tt := range tests {
+		if got := Numbers(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("numbers of %q are %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestValues(t *testing.T) {
+	page := `<ul><li><span>Этаж:</span> <b>3 из 9</b></li><li><span>Балкон</span><b></b></li><li><b>без подписи</b></li></ul>`
+	doc, err := Parse(bytes.NewReader([]byte(page)), "text/html; charset=utf-8", "https://example.com/")
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+
+	values := Values(doc.Selection, "li", "span", "b")
+	want := map[string]string{"Этаж": "3 из 9", "Балкон": ""}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("values %v, want %v", values, want)
+	}
+	if v := Value(values, "Этаж"); v == nil || *v != "3 из 9" {
+		t.Errorf("value of floor %v", v)
+	}
+	if v := Value(values, "Балкон"); v != nil {
+		t.Errorf("empty value is %q", *v)
+	}
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/model"
+)
+
+// LastSection is key of page after the last section, search of it returns model.ErrLastPage
+const LastSection = "last"
+
+// Category is category of ads of profile like sale of flats or rent of offices
+type Category interface {
+	Listing() model.Listing
+	Property() model.PropertyType
+}
+
+// Sections returns sections of profile by regions and categories, categories of listings and
+// property types disabled in config are skipped
+func Sections[C Category, S any](ctx context.Context, code string, categories []C, section func(configs.Region, C) S) []S {
+	cfg := configs.Get(ctx)
+	var parserCfg configs.Parser
+	if cfg != nil {
+		parserCfg = cfg.Parser
+	}
+
+	regions := cfg.ProfileRegions(code)
+	sections := make([]S, 0, len(regions)*len(categories))
+	for _, region := range regions {
+		for _, c := range categories {
+			if parserCfg.ListingEnabled(c.Listing().String()) && parserCfg.PropertyEnabled(c.Property().String()) {
+				sections = append(sections, section(region, c))
+			}
+		}
+	}
+
+	return sections
+}
+
+// Key returns key of section by region, listing and property type of category, key is saved
+// by checkpoint of search instead of index of section which is changed by config
+func Key(region configs.Region, c Category) string {
+	return region.Name + ":" + c.Listing().String() + ":" + c.Property().String()
+}
+
+// Index returns index of section by key of page, page without key is page of the first section,
+// false is returned when section of key is not searched
+func Index[S any](sections []S, key string, keyOf func(S) string) (int, bool) {
+	if key == "" {
+		return 0, len(sections) > 0
+	}
+	for i, s := range sections {
+		if keyOf(s) == key {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// Resumed returns error model.ErrSectionNotFound when section of key saved by checkpoint
+// is not searched any more, as listing, property type or region is disabled in config
+func Resumed[S any](sections []S, key string, keyOf func(S) string) error {
+	if _, ok := Index(sections, key, keyOf); !ok || key == "" {
+		return fmt.Errorf("section %q: %w", key, model.ErrSectionNotFound)
+	}
+
+	return nil
+}
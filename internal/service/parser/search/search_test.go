@@ -0,0 +1,55 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/model"
+)
+
+type category struct {
+	listing  model.Listing
+	property model.PropertyType
+}
+
+func (c category) Listing() model.Listing {
+	return c.listing
+}
+
+func (c category) Property() model.PropertyType {
+	return c.property
+}
+
+func TestIndex(t *testing.T) {
+	minsk := configs.Region{Name: "minsk"}
+	keys := []string{
+		Key(minsk, category{model.ListingSale, model.PropertyFlat}),
+		Key(minsk, category{model.ListingRent, model.PropertyFlat}),
+	}
+	keyOf := func(key string) string { return key }
+
+	tests := []struct {
+		key     string
+		index   int
+		ok      bool
+		resumed error
+	}{
+		{"", 0, true, model.ErrSectionNotFound},
+		{"minsk:sale:flat", 0, true, nil},
+		{"minsk:rent:flat", 1, true, nil},
+		{"minsk:sale:house", 0, false, model.ErrSectionNotFound},
+	}
+	for _, tt := range tests {
+		index, ok := Index(keys, tt.key, keyOf)
+		if index != tt.index || ok != tt.ok {
+			t.Errorf("index of %q is %d %v, want %d %v", tt.key, index, ok, tt.index, tt.ok)
+		}
+		if err := Resumed(keys, tt.key, keyOf); !errors.Is(err, tt.resumed) {
+			t.Errorf("resumed %q error %v, want %v", tt.key, err, tt.resumed)
+		}
+	}
+	if _, ok := Index([]string{}, "", keyOf); ok {
+		t.Error("page without key is found in no sections")
+	}
+}
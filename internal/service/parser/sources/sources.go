@@ -0,0 +1,11 @@
+// Package sources imports built-in sources, each source registers its parser of built-in
+// profile of pkg/ad/profile by its own init, new source is added by import of its package here
+package sources
+
+import (
+	_ "github.com/sku4/ad-parser/internal/service/parser/domovita"
+	_ "github.com/sku4/ad-parser/internal/service/parser/hata"
+	_ "github.com/sku4/ad-parser/internal/service/parser/kufar"
+	_ "github.com/sku4/ad-parser/internal/service/parser/onliner"
+	_ "github.com/sku4/ad-parser/internal/service/parser/realt"
+)
@@ -0,0 +1,15 @@
+package parser
+
+import (
+	"time"
+)
+
+type Status struct {
+	Code     string     `json:"code"`
+	Running  bool       `json:"running"`
+	Stuck    bool       `json:"stuck"`
+	Start    *time.Time `json:"last_start"`
+	Finish   *time.Time `json:"last_finish"`
+	Error    string     `json:"last_error,omitempty"`
+	LastPage bool       `json:"last_page"`
+}
@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"time"
+)
+
+type CleanMode uint8
+
+const (
+	// CleanAuto cleans outdated ads once per clean time
+	CleanAuto CleanMode = iota
+	CleanForce
+	CleanSkip
+)
+
+type Summary struct {
+	Code     string
+	Searched int
+	Saved    int
+	Errors   int
+	LastPage bool
+	Duration time.Duration
+	Err      error
+}
+
+// Failed reports whether the run did not complete without errors
+func (s *Summary) Failed() bool {
+	return s.Err != nil || !s.LastPage || s.Errors > 0
+}
@@ -0,0 +1,260 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sku4/ad-parser/configs"
+	"github.com/sku4/ad-parser/internal/service/parser/limiter"
+	"github.com/sku4/ad-parser/pkg/logger"
+)
+
+const (
+	defaultTimeout    = time.Second * 30
+	defaultBackoffMin = time.Second
+	defaultBackoffMax = time.Second * 30
+	defaultUserAgent  = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) " +
+		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Safari/537.36"
+)
+
+var (
+	defaultClient = New(configs.HTTP{})
+)
+
+// Client is http client shared by all profiles, it retries failed requests,
+// limits rate of requests per host, rotates proxies and user agents
+type Client struct {
+	client     *http.Client
+	cfg        configs.HTTP
+	proxies    []*url.URL
+	proxyIdx   atomic.Uint64
+	userAgents []string
+	uaIdx      atomic.Uint64
+	mu         sync.Mutex
+	limiters   map[string]*limiter.Limiter
+}
+
+func New(cfg configs.HTTP) *Client {
+	c := newClient(cfg)
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = c.proxy
+	c.client.Transport = t
+
+	return c
+}
+
+// NewWithTransport creates client with custom round tripper, proxies are not used
+func NewWithTransport(cfg configs.HTTP, rt http.RoundTripper) *Client {
+	c := newClient(cfg)
+	c.client.Transport = rt
+
+	return c
+}
+
+func newClient(cfg configs.HTTP) *Client {
+	log := logger.Get()
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.BackoffMin <= 0 {
+		cfg.BackoffMin = defaultBackoffMin
+	}
+	if cfg.BackoffMax < cfg.BackoffMin {
+		cfg.BackoffMax = max(defaultBackoffMax, cfg.BackoffMin)
+	}
+
+	proxies := make([]*url.URL, 0, len(cfg.Proxies))
+	for _, p := range cfg.Proxies {
+		proxyURL, err := url.Parse(p)
+		if err != nil {
+			log.Errorf("Proxy '%s' parse error: %s", p, err)
+			continue
+		}
+		proxies = append(proxies, proxyURL)
+	}
+
+	userAgents := cfg.UserAgents
+	if len(userAgents) == 0 {
+		userAgents = []string{defaultUserAgent}
+	}
+
+	return &Client{
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		cfg:        cfg,
+		proxies:    proxies,
+		userAgents: userAgents,
+		limiters:   make(map[string]*limiter.Limiter),
+	}
+}
+
+// Do sends request and retries it on network errors, 5xx and 429 responses
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	hostLimiter := c.limiter(req.URL.Host)
+	for attempt := 0; ; attempt++ {
+		r, err := c.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = hostLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.client.Do(r)
+		last := attempt >= c.cfg.Retries
+		if err != nil {
+			if last || ctx.Err() != nil {
+				return nil, fmt.Errorf("error request after %d attempts: %w", attempt+1, err)
+			}
+			if err = c.wait(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var delay time.Duration
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			hostLimiter.Throttle()
+			delay = c.retryAfter(resp, attempt)
+		case resp.StatusCode >= http.StatusInternalServerError:
+			delay = c.backoff(attempt)
+		default:
+			hostLimiter.Recover()
+			return resp, nil
+		}
+
+		if last {
+			return resp, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if err = c.wait(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Rates returns current requests per second rate by host
+func (c *Client) Rates() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rates := make(map[string]float64, len(c.limiters))
+	for host, l := range c.limiters {
+		rates[host] = l.Rate()
+	}
+
+	return rates
+}
+
+func (c *Client) limiter(host string) *limiter.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.limiters[host]
+	if !ok {
+		l = limiter.New(c.cfg.RateLimit)
+		c.limiters[host] = l
+	}
+
+	return l
+}
+
+func (c *Client) attemptRequest(req *http.Request, attempt int) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("error get request body: %w", err)
+		}
+		r.Body = body
+	}
+
+	if r.Header.Get("User-Agent") == "" {
+		idx := c.uaIdx.Add(1) - 1
+		r.Header.Set("User-Agent", c.userAgents[idx%uint64(len(c.userAgents))])
+	}
+
+	return r, nil
+}
+
+// backoff returns exponential delay with jitter for attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.BackoffMin << min(attempt, 30)
+	if d <= 0 || d > c.cfg.BackoffMax {
+		d = c.cfg.BackoffMax
+	}
+
+	//nolint:gosec
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter returns delay from Retry-After header limited by max backoff
+func (c *Client) retryAfter(resp *http.Response, attempt int) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return c.backoff(attempt)
+	}
+
+	var d time.Duration
+	if sec, err := strconv.Atoi(header); err == nil {
+		d = time.Duration(sec) * time.Second
+	} else if t, errTime := http.ParseTime(header); errTime == nil {
+		d = time.Until(t)
+	} else {
+		return c.backoff(attempt)
+	}
+
+	return min(max(d, 0), c.cfg.BackoffMax)
+}
+
+func (c *Client) proxy(*http.Request) (*url.URL, error) {
+	if len(c.proxies) == 0 {
+		return nil, nil
+	}
+	idx := c.proxyIdx.Add(1) - 1
+
+	return c.proxies[idx%uint64(len(c.proxies))], nil
+}
+
+func (c *Client) wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	return nil
+}
+
+type clientKey struct{}
+
+func Set(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// Get returns client from context, default client is returned when it is not set
+func Get(ctx context.Context) *Client {
+	if c, ok := ctx.Value(clientKey{}).(*Client); ok {
+		return c
+	}
+
+	return defaultClient
+}
@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sku4/ad-parser/configs"
+)
+
+// server responds with statuses in order, the last status is repeated
+type server struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	bodies   []string
+	agents   []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(body))
+	s.agents = append(s.agents, r.Header.Get("User-Agent"))
+
+	status := s.statuses[min(len(s.bodies), len(s.statuses))-1]
+	for k, v := range s.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(status)
+}
+
+func testConfig() configs.HTTP {
+	return configs.HTTP{
+		Retries:    2,
+		BackoffMin: time.Millisecond,
+		BackoffMax: time.Millisecond * 2,
+		RateLimit: configs.RateLimit{
+			RPS:   1000,
+			Burst: 10,
+		},
+	}
+}
+
+func do(t *testing.T, c *Client, method, url, body string) *http.Response {
+	t.Helper()
+
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, url, reqBody)
+	if err != nil {
+		t.Fatalf("new request: %s", err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %s", err)
+	}
+	_ = resp.Body.Close()
+
+	return resp
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		status   int
+		requests int
+	}{
+		{"success", []int{http.StatusOK}, http.StatusOK, 1},
+		{"not found is not retried", []int{http.StatusNotFound}, http.StatusNotFound, 1},
+		{"server error retried", []int{http.StatusBadGateway, http.StatusOK}, http.StatusOK, 2},
+		{"too many requests retried", []int{http.StatusTooManyRequests, http.StatusOK}, http.StatusOK, 2},
+		{"retries exhausted", []int{http.StatusServiceUnavailable}, http.StatusServiceUnavailable, 3},
+	}
+	for _, tt := range tests {
+		s := &server{statuses: tt.statuses}
+		ts := httptest.NewServer(s)
+		resp := do(t, NewWithTransport(testConfig(), http.DefaultTransport), http.MethodGet, ts.URL, "")
+		ts.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+		if len(s.bodies) != tt.requests {
+			t.Errorf("%s: %d requests, want %d", tt.name, len(s.bodies), tt.requests)
+		}
+	}
+}
+
+func TestDoResendsBody(t *testing.T) {
+	s := &server{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	do(t, NewWithTransport(testConfig(), http.DefaultTransport), http.MethodPost, ts.URL, `{"page":1}`)
+	if len(s.bodies) != 3 {
+		t.Fatalf("%d requests, want 3", len(s.bodies))
+	}
+	for i, body := range s.bodies {
+		if body != `{"page":1}` {
+			t.Errorf("request %d body %q, want %q", i, body, `{"page":1}`)
+		}
+	}
+}
+
+func TestDoThrottlesHost(t *testing.T) {
+	s := &server{statuses: []int{http.StatusTooManyRequests, http.StatusOK}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	cfg := testConfig()
+	cfg.RateLimit.Decrease = 0.5
+	cfg.RateLimit.Increase = 1
+	c := NewWithTransport(cfg, http.DefaultTransport)
+	do(t, c, http.MethodGet, ts.URL, "")
+
+	// rate is halved on too many requests response and increased on success
+	for host, rate := range c.Rates() {
+		if want := cfg.RateLimit.RPS*cfg.RateLimit.Decrease + cfg.RateLimit.Increase; rate != want {
+			t.Errorf("host %s rate %v, want %v", host, rate, want)
+		}
+	}
+}
+
+func TestDoRotatesUserAgents(t *testing.T) {
+	s := &server{statuses: []int{http.StatusOK}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	cfg := testConfig()
+	cfg.UserAgents = []string{"agent-1", "agent-2"}
+	c := NewWithTransport(cfg, http.DefaultTransport)
+	for range 3 {
+		do(t, c, http.MethodGet, ts.URL, "")
+	}
+
+	want := []string{"agent-1", "agent-2", "agent-1"}
+	for i, agent := range s.agents {
+		if agent != want[i] {
+			t.Errorf("request %d user agent %q, want %q", i, agent, want[i])
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	c := NewWithTransport(configs.HTTP{BackoffMin: time.Second, BackoffMax: time.Second * 10}, http.DefaultTransport)
+
+	tests := []struct {
+		name   string
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"seconds", "3", time.Second * 3, time.Second * 3},
+		{"limited by max backoff", "60", time.Second * 10, time.Second * 10},
+		{"http date", time.Now().Add(time.Second * 5).UTC().Format(http.TimeFormat), time.Second * 3, time.Second * 5},
+		{"date in the past", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 0},
+		{"invalid is backoff", "soon", time.Second / 2, time.Second},
+		{"missed is backoff", "", time.Second / 2, time.Second},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		if d := c.retryAfter(resp, 0); d < tt.min || d > tt.max {
+			t.Errorf("%s: delay %s, want from %s to %s", tt.name, d, tt.min, tt.max)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := NewWithTransport(configs.HTTP{BackoffMin: time.Second, BackoffMax: time.Second * 4}, http.DefaultTransport)
+
+	for attempt, want := range []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 4} {
+		if d := c.backoff(attempt); d < want/2 || d > want {
+			t.Errorf("attempt %d: backoff %s, want from %s to %s", attempt, d, want/2, want)
+		}
+	}
+}
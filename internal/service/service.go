@@ -11,6 +11,9 @@ import (
 
 type Runner interface {
 	Run(context.Context) error
+	RunOnce(ctx context.Context, codes []string, clean parser.CleanMode) ([]*parser.Summary, error)
+	Status() []*parser.Status
+	MigrateSourceIDs(ctx context.Context) (uint64, error)
 	Shutdown() error
 }
 
@@ -2,34 +2,100 @@ package model
 
 import (
 	"encoding/json"
+	"hash/crc32"
+	"hash/fnv"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
+	dec "github.com/shopspring/decimal"
 	"github.com/tarantool/go-tarantool/v2/datetime"
 	"github.com/tarantool/go-tarantool/v2/decimal"
 )
 
+const (
+	roundPlaces = 2
+)
+
+// Ad is keyed by profile and source id which is native id of ad in source,
+// ExtID is crc32 of url and it is kept only for ads saved before source id,
+// M2Land is area of land plot in square meters
 type Ad struct {
-	ExtID     uint32             `json:"ext_id"`
-	Created   *datetime.Datetime `json:"c_time"`
-	Updated   *datetime.Datetime `json:"u_time"`
-	URL       string             `json:"url"`
-	StreetID  *uint64            `json:"street_id"`
-	House     *string            `json:"house"`
-	LocLat    *float64           `json:"loc_lat"`
-	LocLong   *float64           `json:"loc_long"`
-	Price     *decimal.Decimal   `json:"price"`
-	PriceM2   *decimal.Decimal   `json:"price_m2"`
-	Rooms     *uint8             `json:"rooms"`
-	Floor     *uint8             `json:"floor"`
-	Floors    *uint8             `json:"floors"`
-	Year      *uint16            `json:"year"`
-	Photos    []string           `json:"photos"`
-	M2Main    *float64           `json:"m2_main"`
-	M2Living  *float64           `json:"m2_living"`
-	M2Kitchen *float64           `json:"m2_kitchen"`
-	Bathroom  *string            `json:"bathroom"`
-	Profile   uint16             `json:"profile"`
-	Street    *string            `json:"-"`
+	SourceID   string             `json:"source_id"`
+	ExtID      uint32             `json:"ext_id"`
+	Created    *datetime.Datetime `json:"c_time"`
+	Updated    *datetime.Datetime `json:"u_time"`
+	URL        string             `json:"url"`
+	StreetID   *uint64            `json:"street_id"`
+	House      *string            `json:"house"`
+	LocLat     *float64           `json:"loc_lat"`
+	LocLong    *float64           `json:"loc_long"`
+	Price      *decimal.Decimal   `json:"price"`
+	PriceM2    *decimal.Decimal   `json:"price_m2"`
+	Rooms      *uint8             `json:"rooms"`
+	Floor      *uint8             `json:"floor"`
+	Floors     *uint8             `json:"floors"`
+	Year       *uint16            `json:"year"`
+	Photos     []string           `json:"photos"`
+	M2Main     *float64           `json:"m2_main"`
+	M2Living   *float64           `json:"m2_living"`
+	M2Kitchen  *float64           `json:"m2_kitchen"`
+	M2Land     *float64           `json:"m2_land"`
+	Bathroom   *string            `json:"bathroom"`
+	Profile    uint16             `json:"profile"`
+	Listing    Listing            `json:"listing"`
+	Property   PropertyType       `json:"property_type"`
+	PriceMonth *decimal.Decimal   `json:"price_month"`
+	RentPeriod *RentPeriod        `json:"rent_period"`
+	Owner      *bool              `json:"owner"`
+	Agency     *string            `json:"agency"`
+	Region     string             `json:"region"`
+	GroupID    uint64             `json:"group_id,omitempty"`
+	Street     *string            `json:"-"`
+	// SourceUpdated is update time of ad in source, it is not stored
+	SourceUpdated *time.Time `json:"-"`
+
+	// fields of detail page, they are set by DownloadArticle
+	Description   *string     `json:"description"`
+	Seller        *SellerType `json:"seller"`
+	PhoneHidden   *bool       `json:"phone_hidden"`
+	Material      *string     `json:"material"`
+	CeilingHeight *float64    `json:"ceiling_height"`
+	Balcony       *string     `json:"balcony"`
+	Renovation    *string     `json:"renovation"`
+	Parking       *string     `json:"parking"`
+	// Detailed reports whether detail page is loaded, photos and fields of detail page
+	// of saved ad are kept on update by ad which is not detailed, it is not stored
+	Detailed bool `json:"-"`
+}
+
+// SourceKey is unique key of ad
+type SourceKey struct {
+	Profile  uint16
+	SourceID string
+}
+
+func (ad Ad) SourceKey() SourceKey {
+	return SourceKey{
+		Profile:  ad.Profile,
+		SourceID: ad.SourceID,
+	}
+}
+
+// Hash returns 64-bit hash of key, it is used as id derived from key
+func (k SourceKey) Hash() uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strconv.FormatUint(uint64(k.Profile), 10) + ":" + k.SourceID))
+
+	return h.Sum64()
+}
+
+// SourceIDFunc returns source id of ad of profile by url of ad
+type SourceIDFunc func(profileID uint16, url string) (string, bool)
+
+// LegacyExtID returns ext id of ad by url as it was before source id
+func LegacyExtID(url string) uint32 {
+	return crc32.ChecksumIEEE([]byte(url))
 }
 
 func (ad Ad) ConvertToTuple() (map[string]interface{}, error) {
@@ -48,6 +114,46 @@ func (ad Ad) ConvertToTuple() (map[string]interface{}, error) {
 	adTuple["u_time"] = ad.Updated
 	adTuple["price"] = ad.Price
 	adTuple["price_m2"] = ad.PriceM2
+	adTuple["price_month"] = ad.PriceMonth
+	// group id is hash of source key, it loses precision as float of json
+	if ad.GroupID != 0 {
+		adTuple["group_id"] = ad.GroupID
+	}
 
 	return adTuple, nil
 }
+
+// PriceEqual reports whether prices are equal, no price equals no price only
+func PriceEqual(price1, price2 *decimal.Decimal) bool {
+	if price1 == nil || price2 == nil {
+		return price1 == price2
+	}
+
+	return price1.Equal(price2.Decimal)
+}
+
+// KeepDetail sets photos and fields of detail page of saved ad to ad which is not detailed
+func (ad *Ad) KeepDetail(saved *Ad) {
+	if ad.Detailed {
+		return
+	}
+
+	ad.Photos = saved.Photos
+	ad.Description = saved.Description
+	ad.Seller = saved.Seller
+	ad.PhoneHidden = saved.PhoneHidden
+	ad.Material = saved.Material
+	ad.CeilingHeight = saved.CeilingHeight
+	ad.Balcony = saved.Balcony
+	ad.Renovation = saved.Renovation
+	ad.Parking = saved.Parking
+	ad.Detailed = saved.Detailed
+}
+
+// CalcPriceM2 sets price per square meter by price and main area
+func (ad *Ad) CalcPriceM2() {
+	if ad.Price != nil && ad.M2Main != nil && *ad.M2Main > 0 {
+		m2Main := dec.NewFromFloat(*ad.M2Main)
+		ad.PriceM2 = decimal.NewDecimal(ad.Price.Div(m2Main).Round(roundPlaces))
+	}
+}
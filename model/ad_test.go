@@ -0,0 +1,17 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvertToTupleGroupID(t *testing.T) {
+	ad := Ad{GroupID: math.MaxUint64 - 1}
+	tuple, err := ad.ConvertToTuple()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tuple["group_id"]; got != ad.GroupID {
+		t.Errorf("group_id %v, want %d", got, ad.GroupID)
+	}
+}
@@ -6,4 +6,8 @@ var (
 	ErrLastPage            = errors.New("this is last page")
 	ErrProfileNotMightAuth = errors.New("profile not might auth")
 	ErrTooManyRequests     = errors.New("too many requests")
+	ErrProfileNotFound     = errors.New("profile not found")
+	ErrProfileDisabled     = errors.New("profile is disabled")
+	ErrArticleStatus       = errors.New("unexpected status of article response")
+	ErrSectionNotFound     = errors.New("section of checkpoint is not searched")
 )
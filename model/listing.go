@@ -0,0 +1,61 @@
+package model
+
+type Listing uint8
+
+const (
+	ListingSale Listing = iota + 1
+	ListingRent
+)
+
+var listingCodes = map[Listing]string{
+	ListingSale: "sale",
+	ListingRent: "rent",
+}
+
+func (l Listing) String() string {
+	return listingCodes[l]
+}
+
+type RentPeriod uint8
+
+const (
+	RentPeriodLong RentPeriod = iota + 1
+	RentPeriodDaily
+)
+
+// SellerType is who publishes ad
+type SellerType uint8
+
+const (
+	SellerOwner SellerType = iota + 1
+	SellerAgency
+	SellerDeveloper
+)
+
+// PropertyType is kind of real estate of ad, commercial is premises of sources
+// which don't divide them into offices and retail
+type PropertyType uint8
+
+const (
+	PropertyFlat PropertyType = iota + 1
+	PropertyHouse
+	PropertyOffice
+	PropertyRetail
+	PropertyCommercial
+	PropertyGarage
+	PropertyLand
+)
+
+var propertyCodes = map[PropertyType]string{
+	PropertyFlat:       "flat",
+	PropertyHouse:      "house",
+	PropertyOffice:     "office",
+	PropertyRetail:     "retail",
+	PropertyCommercial: "commercial",
+	PropertyGarage:     "garage",
+	PropertyLand:       "land",
+}
+
+func (p PropertyType) String() string {
+	return propertyCodes[p]
+}
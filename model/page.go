@@ -1,6 +1,39 @@
 package model
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 type Page struct {
 	Num  int
-	Next interface{} // Profile can set data for pagination
+	Next interface{} // Profile can set data for pagination, it must be encodable to JSON
+}
+
+// Checkpoint is search state of profile saved after ads of searched page are saved,
+// search is resumed from it when the previous run did not reach the last page
+type Checkpoint struct {
+	Profile     uint16
+	Num         int
+	Next        json.RawMessage // Page.Next of profile encoded to JSON
+	Started     time.Time       // start of the first run of search
+	Incremental bool            // search skips the rest of section on page of unchanged ads
+}
+
+// Checkpoint returns checkpoint of search which is continued from page
+func (p *Page) Checkpoint(profileID uint16, started time.Time, incremental bool) (*Checkpoint, error) {
+	next, err := json.Marshal(p.Next)
+	if err != nil {
+		return nil, errors.Wrap(err, "checkpoint: next marshal")
+	}
+
+	return &Checkpoint{
+		Profile:     profileID,
+		Num:         p.Num,
+		Next:        next,
+		Started:     started,
+		Incremental: incremental,
+	}, nil
 }
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// AdRecord is json representation of ad with readable times and street name
+type AdRecord struct {
+	Ad
+	Created *time.Time `json:"c_time"`
+	Updated *time.Time `json:"u_time"`
+	Street  *string    `json:"street"`
+}
+
+func (ad Ad) Record() *AdRecord {
+	record := &AdRecord{
+		Ad:     ad,
+		Street: ad.Street,
+	}
+	if ad.Created != nil {
+		created := ad.Created.ToTime()
+		record.Created = &created
+	}
+	if ad.Updated != nil {
+		updated := ad.Updated.ToTime()
+		record.Updated = &updated
+	}
+
+	return record
+}
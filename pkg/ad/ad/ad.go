@@ -2,6 +2,7 @@ package ad
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -56,6 +57,41 @@ func Clean(ctx context.Context, conn pool.Pooler, timeTo time.Time, profileID ui
 	return cnt, nil
 }
 
+// PutBatch inserts new ads and updates existed ones by profile and source id in one call,
+// ad saved before source id is found by ext id and url, results have id and price before
+// update of updated ads, photos and detail fields of updated ad are kept when it is not detailed
+func PutBatch(ctx context.Context, conn pool.Pooler, tuples []map[string]any, detailed []bool, profileID uint16) (
+	[]*PutResultTnt, error) {
+	call := tarantool.NewCallRequest("ad.put_batch").
+		Args([]interface{}{tuples, profileID, detailed}).
+		Context(ctx)
+	resp, err := conn.Do(call, pool.RW).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var putBatchTnt []*PutBatchTnt
+	err = mapstructure.Decode(resp.Data, &putBatchTnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(putBatchTnt) == 0 {
+		return nil, model.ErrParseResponse
+	}
+	batchTnt := putBatchTnt[0]
+
+	if batchTnt.Status != http.StatusOK {
+		return nil, errors.Wrap(model.ErrInternalServerError, batchTnt.Code)
+	}
+
+	if len(batchTnt.Results) != len(tuples) {
+		return nil, errors.Wrap(model.ErrParseResponse, "put batch: results count")
+	}
+
+	return batchTnt.Results, nil
+}
+
 func Filter(ctx context.Context, conn pool.Pooler, fields map[string]any) ([]*model.AdLocationTnt, error) {
 	var after string
 	locs := make([]*model.AdLocationTnt, 0)
@@ -96,3 +132,167 @@ func Filter(ctx context.Context, conn pool.Pooler, fields map[string]any) ([]*mo
 
 	return locs, nil
 }
+
+// Candidates returns ads with the same key for each key, ads of profile of key are not returned
+func Candidates(ctx context.Context, conn pool.Pooler, keys []*model.CandidateKey) ([][]*model.AdTnt, error) {
+	tuples := make([]map[string]any, 0, len(keys))
+	for _, k := range keys {
+		tuples = append(tuples, k.ConvertToTuple())
+	}
+
+	call := tarantool.NewCallRequest("ad.candidates").
+		Args([]interface{}{tuples}).
+		Context(ctx)
+	resp, err := conn.Do(call, pool.PreferRW).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidatesTnt []*CandidatesTnt
+	err = mapstructure.Decode(resp.Data, &candidatesTnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidatesTnt) == 0 {
+		return nil, model.ErrParseResponse
+	}
+	candidateTnt := candidatesTnt[0]
+
+	if candidateTnt.Status != http.StatusOK {
+		return nil, errors.Wrap(model.ErrInternalServerError, candidateTnt.Code)
+	}
+
+	if len(candidateTnt.Candidates) != len(keys) {
+		return nil, errors.Wrap(model.ErrParseResponse, "candidates: results count")
+	}
+
+	return candidateTnt.Candidates, nil
+}
+
+// Listing returns ads of group with all source urls, the earliest ad is canonical one
+func Listing(ctx context.Context, conn pool.Pooler, groupID uint64) (*model.ListingTnt, error) {
+	call := tarantool.NewCallRequest("ad.listing").
+		Args([]interface{}{groupID}).
+		Context(ctx)
+	resp, err := conn.Do(call, pool.PreferRO).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var listingsTnt []*ListingTnt
+	err = mapstructure.Decode(resp.Data, &listingsTnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(listingsTnt) == 0 {
+		return nil, model.ErrParseResponse
+	}
+	listingTnt := listingsTnt[0]
+
+	if listingTnt.Status == http.StatusNotFound {
+		return nil, fmt.Errorf("listing %d: %w", groupID, model.ErrNotFound)
+	}
+	if listingTnt.Status != http.StatusOK {
+		return nil, errors.Wrap(model.ErrInternalServerError, listingTnt.Code)
+	}
+	if len(listingTnt.Ads) == 0 {
+		return nil, fmt.Errorf("listing %d: %w", groupID, model.ErrNotFound)
+	}
+
+	return model.NewListing(groupID, listingTnt.Ads), nil
+}
+
+// BySource returns saved ads of profile with the given source ids, unknown source ids are skipped
+func BySource(ctx context.Context, conn pool.Pooler, profileID uint16, sourceIDs []string) ([]*model.AdTnt, error) {
+	call := tarantool.NewCallRequest("ad.by_source").
+		Args([]interface{}{profileID, sourceIDs}).
+		Context(ctx)
+	resp, err := conn.Do(call, pool.PreferRO).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var bySourceTnt []*BySourceTnt
+	err = mapstructure.Decode(resp.Data, &bySourceTnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bySourceTnt) == 0 {
+		return nil, model.ErrParseResponse
+	}
+	bySource := bySourceTnt[0]
+
+	if bySource.Status != http.StatusOK {
+		return nil, errors.Wrap(model.ErrInternalServerError, bySource.Code)
+	}
+
+	return bySource.Ads, nil
+}
+
+// WithoutSourceID returns ads saved before source id ordered by id
+func WithoutSourceID(ctx context.Context, conn pool.Pooler, after uint64, limit int) ([]*model.AdSourceTnt, error) {
+	call := tarantool.NewCallRequest("ad.without_source_id").
+		Args([]interface{}{after, limit}).
+		Context(ctx)
+	resp, err := conn.Do(call, pool.PreferRO).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var withoutTnt []*WithoutSourceIDTnt
+	err = mapstructure.Decode(resp.Data, &withoutTnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(withoutTnt) == 0 {
+		return nil, model.ErrParseResponse
+	}
+	without := withoutTnt[0]
+
+	if without.Status != http.StatusOK {
+		return nil, errors.Wrap(model.ErrInternalServerError, without.Code)
+	}
+
+	return without.Ads, nil
+}
+
+// SetSourceIDs sets source ids of ads saved before it and moves ads of group ext id to group id,
+// the procedure deletes ad when ad with the same source id is saved already as it is the same ad of source
+func SetSourceIDs(ctx context.Context, conn pool.Pooler, ads []*model.AdSourceTnt) error {
+	tuples := make([]map[string]any, 0, len(ads))
+	for _, ad := range ads {
+		tuples = append(tuples, map[string]any{
+			"id":        ad.ID,
+			"ext_id":    ad.ExtID,
+			"source_id": ad.SourceID,
+			"group_id":  ad.GroupID,
+		})
+	}
+
+	call := tarantool.NewCallRequest("ad.set_source_ids").
+		Args([]interface{}{tuples}).
+		Context(ctx)
+	resp, err := conn.Do(call, pool.RW).Get()
+	if err != nil {
+		return err
+	}
+
+	var setTnt []*SetSourceIDsTnt
+	err = mapstructure.Decode(resp.Data, &setTnt)
+	if err != nil {
+		return err
+	}
+
+	if len(setTnt) == 0 {
+		return model.ErrParseResponse
+	}
+	if setTnt[0].Status != http.StatusOK {
+		return errors.Wrap(model.ErrInternalServerError, setTnt[0].Code)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/tarantool/go-tarantool/v2/decimal"
 )
 
 type CleanTnt struct {
@@ -39,3 +40,49 @@ type FilterTnt struct {
 	After     string                 `mapstructure:"after"`
 	Locations []*model.AdLocationTnt `mapstructure:"ads"`
 }
+
+type PutBatchTnt struct {
+	Status  int             `mapstructure:"status"`
+	Code    string          `mapstructure:"code"`
+	Results []*PutResultTnt `mapstructure:"results"`
+}
+
+// PutResultTnt is result of put of one ad in batch, results are in order of ads,
+// price old is price of updated ad before put
+type PutResultTnt struct {
+	ID       uint64           `mapstructure:"id"`
+	SourceID string           `mapstructure:"source_id"`
+	Status   int              `mapstructure:"status"`
+	Code     string           `mapstructure:"code"`
+	New      bool             `mapstructure:"new"`
+	PriceOld *decimal.Decimal `mapstructure:"price_old"`
+}
+
+type CandidatesTnt struct {
+	Status     int              `mapstructure:"status"`
+	Code       string           `mapstructure:"code"`
+	Candidates [][]*model.AdTnt `mapstructure:"candidates"`
+}
+
+type ListingTnt struct {
+	Status int            `mapstructure:"status"`
+	Code   string         `mapstructure:"code"`
+	Ads    []*model.AdTnt `mapstructure:"ads"`
+}
+
+type BySourceTnt struct {
+	Status int            `mapstructure:"status"`
+	Code   string         `mapstructure:"code"`
+	Ads    []*model.AdTnt `mapstructure:"ads"`
+}
+
+type WithoutSourceIDTnt struct {
+	Status int                  `mapstructure:"status"`
+	Code   string               `mapstructure:"code"`
+	Ads    []*model.AdSourceTnt `mapstructure:"ads"`
+}
+
+type SetSourceIDsTnt struct {
+	Status int    `mapstructure:"status"`
+	Code   string `mapstructure:"code"`
+}
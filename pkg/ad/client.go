@@ -6,6 +6,7 @@ import (
 
 	"github.com/sku4/ad-parser/pkg/ad/ad"
 	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/sku4/ad-parser/pkg/ad/price"
 	"github.com/sku4/ad-parser/pkg/ad/profile"
 	"github.com/sku4/ad-parser/pkg/ad/street"
 	"github.com/sku4/ad-parser/pkg/ad/subscription"
@@ -26,6 +27,10 @@ func (c *Client) StreetGetID(ctx context.Context, name string) (*street.ID, erro
 	return street.GetID(ctx, c.conn, name)
 }
 
+func (c *Client) StreetGetIDs(ctx context.Context, names []string) (map[string]uint64, error) {
+	return street.GetIDs(ctx, c.conn, names)
+}
+
 func (c *Client) StreetGetTypes(ctx context.Context) (map[uint8]*street.Type, error) {
 	return street.GetTypes(ctx, c.conn)
 }
@@ -38,10 +43,48 @@ func (c *Client) AdsClean(ctx context.Context, timeTo time.Time, profileID uint1
 	return ad.Clean(ctx, c.conn, timeTo, profileID)
 }
 
+func (c *Client) AdPutBatch(ctx context.Context, tuples []map[string]any, detailed []bool, profileID uint16) (
+	[]*ad.PutResultTnt, error) {
+	return ad.PutBatch(ctx, c.conn, tuples, detailed, profileID)
+}
+
 func (c *Client) AdFilter(ctx context.Context, fields map[string]any) ([]*model.AdLocationTnt, error) {
 	return ad.Filter(ctx, c.conn, fields)
 }
 
+func (c *Client) AdCandidates(ctx context.Context, keys []*model.CandidateKey) ([][]*model.AdTnt, error) {
+	return ad.Candidates(ctx, c.conn, keys)
+}
+
+// AdListing returns canonical listing of apartment with urls of all sources
+func (c *Client) AdListing(ctx context.Context, groupID uint64) (*model.ListingTnt, error) {
+	return ad.Listing(ctx, c.conn, groupID)
+}
+
+func (c *Client) AdsBySource(ctx context.Context, profileID uint16, sourceIDs []string) ([]*model.AdTnt, error) {
+	return ad.BySource(ctx, c.conn, profileID, sourceIDs)
+}
+
+func (c *Client) AdsWithoutSourceID(ctx context.Context, after uint64, limit int) ([]*model.AdSourceTnt, error) {
+	return ad.WithoutSourceID(ctx, c.conn, after, limit)
+}
+
+func (c *Client) AdSetSourceIDs(ctx context.Context, ads []*model.AdSourceTnt) error {
+	return ad.SetSourceIDs(ctx, c.conn, ads)
+}
+
+func (c *Client) PricePut(ctx context.Context, adPrice *model.AdPriceTnt) error {
+	return price.Put(ctx, c.conn, adPrice)
+}
+
+func (c *Client) PriceHistory(ctx context.Context, adID uint64) ([]*model.AdPriceTnt, error) {
+	return price.History(ctx, c.conn, adID)
+}
+
+func (c *Client) PriceDrops(ctx context.Context, timeFrom, timeTo time.Time) ([]*model.AdPriceTnt, error) {
+	return price.Drops(ctx, c.conn, timeFrom, timeTo)
+}
+
 func (c *Client) ProfileGetByCode(ctx context.Context, code string) uint16 {
 	return profile.GetByCode(ctx, code)
 }
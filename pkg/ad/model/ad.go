@@ -6,28 +6,57 @@ import (
 )
 
 type AdTnt struct {
-	ID          uint64             `mapstructure:"id" json:"id"`
-	ExtID       uint32             `mapstructure:"ext_id" json:"ext_id"`
-	Created     *datetime.Datetime `mapstructure:"c_time" json:"c_time"`
-	Updated     *datetime.Datetime `mapstructure:"u_time" json:"u_time"`
-	QueueStatus string             `mapstructure:"nq_status" json:"nq_status"`
-	URL         string             `mapstructure:"url" json:"url"`
-	StreetID    *uint64            `mapstructure:"street_id" json:"street_id"`
-	House       *string            `mapstructure:"house" json:"house"`
-	LocLat      *float64           `mapstructure:"loc_lat" json:"loc_lat"`
-	LocLong     *float64           `mapstructure:"loc_long" json:"loc_long"`
-	Price       *decimal.Decimal   `mapstructure:"price" json:"price"`
-	PriceM2     *decimal.Decimal   `mapstructure:"price_m2" json:"price_m2"`
-	Rooms       *uint8             `mapstructure:"rooms" json:"rooms"`
-	Floor       *uint8             `mapstructure:"floor" json:"floor"`
-	Floors      *uint8             `mapstructure:"floors" json:"floors"`
-	Year        *uint16            `mapstructure:"year" json:"year"`
-	Photos      []string           `mapstructure:"photos" json:"photos"`
-	M2Main      *float64           `mapstructure:"m2_main" json:"m2_main"`
-	M2Living    *float64           `mapstructure:"m2_living" json:"m2_living"`
-	M2Kitchen   *float64           `mapstructure:"m2_kitchen" json:"m2_kitchen"`
-	Bathroom    *string            `mapstructure:"bathroom" json:"bathroom"`
-	Profile     uint16             `mapstructure:"profile" json:"profile"`
+	ID            uint64             `mapstructure:"id" json:"id"`
+	ExtID         uint32             `mapstructure:"ext_id" json:"ext_id"`
+	Created       *datetime.Datetime `mapstructure:"c_time" json:"c_time"`
+	Updated       *datetime.Datetime `mapstructure:"u_time" json:"u_time"`
+	QueueStatus   string             `mapstructure:"nq_status" json:"nq_status"`
+	URL           string             `mapstructure:"url" json:"url"`
+	StreetID      *uint64            `mapstructure:"street_id" json:"street_id"`
+	House         *string            `mapstructure:"house" json:"house"`
+	LocLat        *float64           `mapstructure:"loc_lat" json:"loc_lat"`
+	LocLong       *float64           `mapstructure:"loc_long" json:"loc_long"`
+	Price         *decimal.Decimal   `mapstructure:"price" json:"price"`
+	PriceM2       *decimal.Decimal   `mapstructure:"price_m2" json:"price_m2"`
+	Rooms         *uint8             `mapstructure:"rooms" json:"rooms"`
+	Floor         *uint8             `mapstructure:"floor" json:"floor"`
+	Floors        *uint8             `mapstructure:"floors" json:"floors"`
+	Year          *uint16            `mapstructure:"year" json:"year"`
+	Photos        []string           `mapstructure:"photos" json:"photos"`
+	M2Main        *float64           `mapstructure:"m2_main" json:"m2_main"`
+	M2Living      *float64           `mapstructure:"m2_living" json:"m2_living"`
+	M2Kitchen     *float64           `mapstructure:"m2_kitchen" json:"m2_kitchen"`
+	Bathroom      *string            `mapstructure:"bathroom" json:"bathroom"`
+	Profile       uint16             `mapstructure:"profile" json:"profile"`
+	Listing       uint8              `mapstructure:"listing" json:"listing"`
+	PriceMonth    *decimal.Decimal   `mapstructure:"price_month" json:"price_month"`
+	RentPeriod    *uint8             `mapstructure:"rent_period" json:"rent_period"`
+	Owner         *bool              `mapstructure:"owner" json:"owner"`
+	Agency        *string            `mapstructure:"agency" json:"agency"`
+	Region        string             `mapstructure:"region" json:"region"`
+	GroupID       uint64             `mapstructure:"group_id" json:"group_id"`
+	SourceID      string             `mapstructure:"source_id" json:"source_id"`
+	Description   *string            `mapstructure:"description" json:"description"`
+	Seller        *uint8             `mapstructure:"seller" json:"seller"`
+	PhoneHidden   *bool              `mapstructure:"phone_hidden" json:"phone_hidden"`
+	Material      *string            `mapstructure:"material" json:"material"`
+	CeilingHeight *float64           `mapstructure:"ceiling_height" json:"ceiling_height"`
+	Balcony       *string            `mapstructure:"balcony" json:"balcony"`
+	Renovation    *string            `mapstructure:"renovation" json:"renovation"`
+	Parking       *string            `mapstructure:"parking" json:"parking"`
+	Property      uint8              `mapstructure:"property_type" json:"property_type"`
+	M2Land        *float64           `mapstructure:"m2_land" json:"m2_land"`
+}
+
+// AdSourceTnt is ad saved before source id, source id is set by url of ad and
+// group of ad keyed by its ext id is moved to group id derived from source id
+type AdSourceTnt struct {
+	ID       uint64 `mapstructure:"id" json:"id"`
+	ExtID    uint32 `mapstructure:"ext_id" json:"ext_id"`
+	Profile  uint16 `mapstructure:"profile" json:"profile"`
+	URL      string `mapstructure:"url" json:"url"`
+	SourceID string `mapstructure:"source_id" json:"source_id"`
+	GroupID  uint64 `mapstructure:"group_id" json:"group_id"`
 }
 
 type AdLocationTnt struct {
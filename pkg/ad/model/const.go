@@ -1,30 +1,52 @@
 package model
 
 const (
-	SpaceAd               = "ad"
-	SpaceStreet           = "street"
-	SpaceSubscription     = "subscription"
-	IndexExt              = "ext"
-	IndexPrimary          = "primary"
-	IndexType             = "type"
-	IndexUniq             = "uniq"
-	EventNewAd            = "event_new_ad"
-	SpaceAdFieldUTime     = 3
-	SpaceAdFieldStreetID  = 6
-	SpaceAdFieldHouse     = 7
-	SpaceAdFieldLocLat    = 8
-	SpaceAdFieldLocLong   = 9
-	SpaceAdFieldPrice     = 10
-	SpaceAdFieldPriceM2   = 11
-	SpaceAdFieldRooms     = 12
-	SpaceAdFieldFloor     = 13
-	SpaceAdFieldFloors    = 14
-	SpaceAdFieldYear      = 15
-	SpaceAdFieldPhotos    = 16
-	SpaceAdFieldM2Main    = 17
-	SpaceAdFieldM2Living  = 18
-	SpaceAdFieldM2Kitchen = 19
-	SpaceAdFieldBathroom  = 20
-	SpaceSubID            = "id"
-	SpaceSubTgID          = "tg_id"
+	SpaceAd                = "ad"
+	SpaceStreet            = "street"
+	SpaceSubscription      = "subscription"
+	SpaceAdPrice           = "ad_price"
+	IndexExt               = "ext"
+	IndexSource            = "source"
+	IndexPrimary           = "primary"
+	IndexType              = "type"
+	IndexUniq              = "uniq"
+	IndexAd                = "ad"
+	EventNewAd             = "event_new_ad"
+	SpaceAdFieldUTime      = 3
+	SpaceAdFieldStreetID   = 6
+	SpaceAdFieldHouse      = 7
+	SpaceAdFieldLocLat     = 8
+	SpaceAdFieldLocLong    = 9
+	SpaceAdFieldPrice      = 10
+	SpaceAdFieldPriceM2    = 11
+	SpaceAdFieldRooms      = 12
+	SpaceAdFieldFloor      = 13
+	SpaceAdFieldFloors     = 14
+	SpaceAdFieldYear       = 15
+	SpaceAdFieldPhotos     = 16
+	SpaceAdFieldM2Main     = 17
+	SpaceAdFieldM2Living   = 18
+	SpaceAdFieldM2Kitchen  = 19
+	SpaceAdFieldBathroom   = 20
+	SpaceAdFieldListing    = 22
+	SpaceAdFieldPriceMonth = 23
+	SpaceAdFieldRentPeriod = 24
+	SpaceAdFieldOwner      = 25
+	SpaceAdFieldAgency     = 26
+	SpaceAdFieldRegion     = 27
+	SpaceAdFieldGroupID    = 28
+	SpaceAdFieldSourceID   = 29
+	SpaceAdFieldDesc       = 30
+	SpaceAdFieldSeller     = 31
+	SpaceAdFieldPhone      = 32
+	SpaceAdFieldMaterial   = 33
+	SpaceAdFieldCeiling    = 34
+	SpaceAdFieldBalcony    = 35
+	SpaceAdFieldRenovation = 36
+	SpaceAdFieldParking    = 37
+	SpaceAdFieldProperty   = 38
+	SpaceAdFieldM2Land     = 39
+	AdFilterFieldListing   = "listing"
+	SpaceSubID             = "id"
+	SpaceSubTgID           = "tg_id"
 )
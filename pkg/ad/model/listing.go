@@ -0,0 +1,70 @@
+package model
+
+import (
+	"sort"
+)
+
+// CandidateKey is exact part of ad which is matched by duplicates search,
+// area and location are compared by tolerance after ads are found by key,
+// ads of profile of key are not candidates
+type CandidateKey struct {
+	Profile  uint16 `mapstructure:"profile" json:"profile"`
+	StreetID uint64 `mapstructure:"street_id" json:"street_id"`
+	House    string `mapstructure:"house" json:"house"`
+	Floor    uint8  `mapstructure:"floor" json:"floor"`
+	Rooms    uint8  `mapstructure:"rooms" json:"rooms"`
+	Listing  uint8  `mapstructure:"listing" json:"listing"`
+	Property uint8  `mapstructure:"property_type" json:"property_type"`
+}
+
+// ListingTnt is one apartment listed by several sources
+type ListingTnt struct {
+	GroupID uint64   `json:"group_id"`
+	Ad      *AdTnt   `json:"ad"`
+	URLs    []string `json:"urls"`
+	Ads     []*AdTnt `json:"ads"`
+}
+
+// NewListing makes listing of ads of one group, the earliest ad is canonical one
+func NewListing(groupID uint64, ads []*AdTnt) *ListingTnt {
+	sort.SliceStable(ads, func(i, j int) bool {
+		ci, cj := ads[i].Created, ads[j].Created
+		switch {
+		case ci != nil && cj != nil && !ci.ToTime().Equal(cj.ToTime()):
+			return ci.ToTime().Before(cj.ToTime())
+		case ci != nil && cj == nil:
+			return true
+		case ci == nil && cj != nil:
+			return false
+		}
+		return ads[i].ID < ads[j].ID
+	})
+
+	urls := make([]string, 0, len(ads))
+	for _, a := range ads {
+		urls = append(urls, a.URL)
+	}
+
+	listing := &ListingTnt{
+		GroupID: groupID,
+		URLs:    urls,
+		Ads:     ads,
+	}
+	if len(ads) > 0 {
+		listing.Ad = ads[0]
+	}
+
+	return listing
+}
+
+func (k CandidateKey) ConvertToTuple() map[string]any {
+	return map[string]any{
+		"profile":       k.Profile,
+		"street_id":     k.StreetID,
+		"house":         k.House,
+		"floor":         k.Floor,
+		"rooms":         k.Rooms,
+		"listing":       k.Listing,
+		"property_type": k.Property,
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+type AdPriceTnt struct {
+	ID       uint64             `mapstructure:"id" json:"id"`
+	AdID     uint64             `mapstructure:"ad_id" json:"ad_id"`
+	Created  *datetime.Datetime `mapstructure:"c_time" json:"c_time"`
+	PriceOld *decimal.Decimal   `mapstructure:"price_old" json:"price_old"`
+	PriceNew *decimal.Decimal   `mapstructure:"price_new" json:"price_new"`
+	Profile  uint16             `mapstructure:"profile" json:"profile"`
+}
+
+func (p AdPriceTnt) ConvertToInsertTuple() []interface{} {
+	return []interface{}{
+		nil, // ID
+		uint(p.AdID),
+		p.Created,
+		p.PriceOld,
+		p.PriceNew,
+		p.Profile,
+	}
+}
+
+// Dropped reports whether the price went down
+func (p AdPriceTnt) Dropped() bool {
+	return p.PriceOld != nil && p.PriceNew != nil && p.PriceNew.LessThan(p.PriceOld.Decimal)
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+const (
+	batchLimitClean = 10000
+	rangeFrom       = "_from"
+	rangeTo         = "_to"
+)
+
+var (
+	// adFilterColumns are columns of ad which can be used by filter, value is sql type of column
+	adFilterColumns = map[string]string{
+		"street_id":                "bigint",
+		"house":                    "text",
+		"price":                    "numeric",
+		"price_m2":                 "numeric",
+		"rooms":                    "smallint",
+		"floor":                    "smallint",
+		"floors":                   "smallint",
+		"year":                     "smallint",
+		"m2_main":                  "double precision",
+		"m2_land":                  "double precision",
+		"property_type":            "smallint",
+		"profile":                  "smallint",
+		"region":                   "text",
+		"rent_period":              "smallint",
+		"owner":                    "boolean",
+		"price_month":              "numeric",
+		model.AdFilterFieldListing: "smallint",
+	}
+)
+
+func AdsClean(ctx context.Context, conn Conn, timeTo time.Time, profileID uint16) (uint64, error) {
+	var cnt uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return cnt, nil
+		default:
+		}
+
+		tag, err := conn.Exec(ctx, `DELETE FROM ad WHERE id IN (
+			SELECT id FROM ad WHERE profile = $1 AND u_time < $2 LIMIT $3
+		)`, profileID, timeTo.UTC(), batchLimitClean)
+		if err != nil {
+			return cnt, err
+		}
+
+		cnt += uint64(tag.RowsAffected())
+		if tag.RowsAffected() < batchLimitClean {
+			break
+		}
+	}
+
+	return cnt, nil
+}
+
+// AdFilter returns locations of ads by fields, field is compared by equality
+// or as range when it has _from or _to suffix, ads with the same location are grouped
+func AdFilter(ctx context.Context, conn Conn, fields map[string]any) ([]*model.AdLocationTnt, error) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	where := []string{"loc_lat IS NOT NULL", "loc_long IS NOT NULL"}
+	args := make([]any, 0, len(fields))
+	for _, k := range keys {
+		column, op := k, "="
+		if c, ok := strings.CutSuffix(k, rangeFrom); ok {
+			column, op = c, ">="
+		} else if c, ok = strings.CutSuffix(k, rangeTo); ok {
+			column, op = c, "<="
+		}
+		sqlType, ok := adFilterColumns[column]
+		if !ok {
+			return nil, errors.Wrap(model.ErrInternalServerError, fmt.Sprintf("unknown filter field %s", k))
+		}
+
+		args = append(args, filterArg(fields[k]))
+		where = append(where, fmt.Sprintf("%s %s $%d::%s", column, op, len(args), sqlType))
+	}
+
+	//nolint:gosec
+	rows, err := conn.Query(ctx, fmt.Sprintf(`SELECT loc_lat, loc_long, array_agg(id ORDER BY id)
+		FROM ad WHERE %s GROUP BY loc_lat, loc_long`, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locs := make([]*model.AdLocationTnt, 0)
+	for rows.Next() {
+		loc := &model.AdLocationTnt{}
+		var ids []int64
+		if err = rows.Scan(&loc.LocLat, &loc.LocLong, &ids); err != nil {
+			return nil, err
+		}
+		if len(ids) == 1 {
+			id := uint64(ids[0])
+			loc.ID = &id
+		} else {
+			loc.IDs = make([]uint64, 0, len(ids))
+			for _, id := range ids {
+				loc.IDs = append(loc.IDs, uint64(id))
+			}
+		}
+		locs = append(locs, loc)
+	}
+
+	return locs, rows.Err()
+}
+
+// filterArg converts decimals to text, other values are passed as is
+func filterArg(v any) any {
+	switch d := v.(type) {
+	case *decimal.Decimal:
+		return Numeric(d)
+	case decimal.Decimal:
+		return Numeric(&d)
+	}
+
+	return v
+}
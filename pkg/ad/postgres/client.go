@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/sku4/ad-parser/pkg/ad/profile"
+	"github.com/sku4/ad-parser/pkg/ad/street"
+	"github.com/sku4/ad-parser/pkg/ad/subscription"
+)
+
+// Conn is implemented by pool, connection and transaction,
+// so operations can be called inside of transaction
+type Conn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Client has the same operations as tarantool client of ad package
+type Client struct {
+	conn *pgxpool.Pool
+}
+
+func NewClient(conn *pgxpool.Pool) *Client {
+	return &Client{
+		conn: conn,
+	}
+}
+
+func (c *Client) StreetGetID(ctx context.Context, name string) (*street.ID, error) {
+	return StreetGetID(ctx, c.conn, name)
+}
+
+func (c *Client) StreetGetTypes(ctx context.Context) (map[uint8]*street.Type, error) {
+	return StreetGetTypes(ctx, c.conn)
+}
+
+func (c *Client) StreetGet(ctx context.Context, id uint64) (*street.Ext, error) {
+	return StreetGet(ctx, c.conn, id)
+}
+
+func (c *Client) AdsClean(ctx context.Context, timeTo time.Time, profileID uint16) (uint64, error) {
+	return AdsClean(ctx, c.conn, timeTo, profileID)
+}
+
+func (c *Client) AdFilter(ctx context.Context, fields map[string]any) ([]*model.AdLocationTnt, error) {
+	return AdFilter(ctx, c.conn, fields)
+}
+
+func (c *Client) AdCandidates(ctx context.Context, keys []*model.CandidateKey) ([][]*model.AdTnt, error) {
+	return AdCandidates(ctx, c.conn, keys)
+}
+
+// AdListing returns canonical listing of apartment with urls of all sources
+func (c *Client) AdListing(ctx context.Context, groupID uint64) (*model.ListingTnt, error) {
+	return AdListing(ctx, c.conn, groupID)
+}
+
+func (c *Client) AdsBySource(ctx context.Context, profileID uint16, sourceIDs []string) ([]*model.AdTnt, error) {
+	return AdsBySource(ctx, c.conn, profileID, sourceIDs)
+}
+
+func (c *Client) AdsWithoutSourceID(ctx context.Context, after uint64, limit int) ([]*model.AdSourceTnt, error) {
+	return AdsWithoutSourceID(ctx, c.conn, after, limit)
+}
+
+func (c *Client) AdSetSourceIDs(ctx context.Context, ads []*model.AdSourceTnt) error {
+	return AdSetSourceIDs(ctx, c.conn, ads)
+}
+
+func (c *Client) PricePut(ctx context.Context, adPrice *model.AdPriceTnt) error {
+	return PricePut(ctx, c.conn, adPrice)
+}
+
+func (c *Client) PriceHistory(ctx context.Context, adID uint64) ([]*model.AdPriceTnt, error) {
+	return PriceHistory(ctx, c.conn, adID)
+}
+
+func (c *Client) PriceDrops(ctx context.Context, timeFrom, timeTo time.Time) ([]*model.AdPriceTnt, error) {
+	return PriceDrops(ctx, c.conn, timeFrom, timeTo)
+}
+
+func (c *Client) ProfileGetByCode(ctx context.Context, code string) uint16 {
+	return profile.GetByCode(ctx, code)
+}
+
+func (c *Client) ProfileGetByID(ctx context.Context, id uint16) string {
+	return profile.GetByID(ctx, id)
+}
+
+func (c *Client) SubscriptionFilter(ctx context.Context, fields map[string]any) ([]int64, error) {
+	return SubscriptionFilter(ctx, c.conn, fields)
+}
+
+func (c *Client) SubscriptionGetByTgID(ctx context.Context, tgID int64, limit int, after string) (
+	*subscription.GetByTgIDTnt, error) {
+	return SubscriptionGetByTgID(ctx, c.conn, tgID, limit, after)
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// Numeric returns decimal as text argument of numeric column
+func Numeric(d *decimal.Decimal) *string {
+	if d == nil {
+		return nil
+	}
+	s := d.String()
+
+	return &s
+}
+
+// Time returns datetime as argument of timestamptz column
+func Time(d *datetime.Datetime) *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.ToTime().UTC()
+
+	return &t
+}
+
+// ToDecimal converts numeric column selected as text to decimal
+func ToDecimal(s *string) (*decimal.Decimal, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	return decimal.NewDecimalFromString(*s)
+}
+
+// ToDatetime converts timestamptz column to datetime
+func ToDatetime(t *time.Time) (*datetime.Datetime, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	return datetime.NewDatetime(t.UTC())
+}
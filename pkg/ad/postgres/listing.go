@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+)
+
+const (
+	adTntColumns = "id, ext_id, c_time, u_time, url, street_id, house, loc_lat, loc_long, " +
+		"price::text, price_m2::text, rooms, floor, floors, year, photos, m2_main, m2_living, m2_kitchen, " +
+		"bathroom, profile, listing, price_month::text, rent_period, owner, agency, region, group_id, source_id, " +
+		"description, seller, phone_hidden, material, ceiling_height, balcony, renovation, parking, " +
+		"property_type, m2_land"
+)
+
+// AdCandidates returns ads with the same key for each key in one query over unnested keys,
+// ads of profile of key are not returned
+func AdCandidates(ctx context.Context, conn Conn, keys []*model.CandidateKey) ([][]*model.AdTnt, error) {
+	streetIDs := make([]int64, 0, len(keys))
+	houses := make([]string, 0, len(keys))
+	floors := make([]int16, 0, len(keys))
+	rooms := make([]int16, 0, len(keys))
+	listings := make([]int16, 0, len(keys))
+	properties := make([]int16, 0, len(keys))
+	profiles := make([]int16, 0, len(keys))
+	for _, k := range keys {
+		streetIDs = append(streetIDs, int64(k.StreetID))
+		houses = append(houses, k.House)
+		floors = append(floors, int16(k.Floor))
+		rooms = append(rooms, int16(k.Rooms))
+		listings = append(listings, int16(k.Listing))
+		properties = append(properties, int16(k.Property))
+		profiles = append(profiles, int16(k.Profile))
+	}
+
+	rows, err := conn.Query(ctx, `SELECT k.idx, c.* FROM unnest($1::bigint[], $2::text[], $3::smallint[],
+		$4::smallint[], $5::smallint[], $6::smallint[], $7::smallint[]) WITH ORDINALITY
+		AS k(k_street_id, k_house, k_floor, k_rooms, k_listing, k_property_type, k_profile, idx)
+		CROSS JOIN LATERAL (SELECT `+adTntColumns+` FROM ad
+			WHERE street_id = k_street_id AND house = k_house AND floor = k_floor AND rooms = k_rooms
+			AND listing = k_listing AND property_type = k_property_type AND profile <> k_profile) c
+		ORDER BY k.idx, c.group_id`,
+		streetIDs, houses, floors, rooms, listings, properties, profiles)
+	if err != nil {
+		return nil, errors.Wrap(err, "candidates: select")
+	}
+	defer rows.Close()
+
+	candidates := make([][]*model.AdTnt, len(keys))
+	for i := range candidates {
+		candidates[i] = make([]*model.AdTnt, 0)
+	}
+	for rows.Next() {
+		var idx int64
+		ad, errScan := scanAd(rows, &idx)
+		if errScan != nil {
+			return nil, errors.Wrap(errScan, "candidates: scan")
+		}
+		if idx < 1 || int(idx) > len(keys) {
+			return nil, errors.Wrap(model.ErrParseResponse, "candidates: key index")
+		}
+		candidates[idx-1] = append(candidates[idx-1], ad)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "candidates: rows")
+	}
+
+	return candidates, nil
+}
+
+// AdListing returns ads of group with all source urls, the earliest ad is canonical one
+func AdListing(ctx context.Context, conn Conn, groupID uint64) (*model.ListingTnt, error) {
+	rows, err := conn.Query(ctx, "SELECT "+adTntColumns+" FROM ad WHERE group_id = $1", int64(groupID))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("listing: select %d", groupID))
+	}
+
+	ads, err := scanAds(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("listing: scan %d", groupID))
+	}
+	if len(ads) == 0 {
+		return nil, fmt.Errorf("listing %d: %w", groupID, model.ErrNotFound)
+	}
+
+	return model.NewListing(groupID, ads), nil
+}
+
+func scanAds(rows pgx.Rows) ([]*model.AdTnt, error) {
+	defer rows.Close()
+
+	ads := make([]*model.AdTnt, 0)
+	for rows.Next() {
+		ad, err := scanAd(rows)
+		if err != nil {
+			return nil, err
+		}
+		ads = append(ads, ad)
+	}
+
+	return ads, rows.Err()
+}
+
+// scanAd scans ad of adTntColumns, columns selected before them are scanned to lead
+func scanAd(row pgx.Row, lead ...any) (*model.AdTnt, error) {
+	var ad model.AdTnt
+	var created, updated *time.Time
+	var price, priceM2, priceMonth, sourceID *string
+	dest := append(make([]any, 0, len(lead)), lead...)
+	dest = append(dest, &ad.ID, &ad.ExtID, &created, &updated, &ad.URL, &ad.StreetID, &ad.House,
+		&ad.LocLat, &ad.LocLong, &price, &priceM2, &ad.Rooms, &ad.Floor, &ad.Floors, &ad.Year,
+		&ad.Photos, &ad.M2Main, &ad.M2Living, &ad.M2Kitchen, &ad.Bathroom, &ad.Profile,
+		&ad.Listing, &priceMonth, &ad.RentPeriod, &ad.Owner, &ad.Agency, &ad.Region, &ad.GroupID,
+		&sourceID, &ad.Description, &ad.Seller, &ad.PhoneHidden, &ad.Material, &ad.CeilingHeight,
+		&ad.Balcony, &ad.Renovation, &ad.Parking, &ad.Property, &ad.M2Land)
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	if sourceID != nil {
+		ad.SourceID = *sourceID
+	}
+
+	if ad.Created, err = ToDatetime(created); err != nil {
+		return nil, err
+	}
+	if ad.Updated, err = ToDatetime(updated); err != nil {
+		return nil, err
+	}
+	if ad.Price, err = ToDecimal(price); err != nil {
+		return nil, err
+	}
+	if ad.PriceM2, err = ToDecimal(priceM2); err != nil {
+		return nil, err
+	}
+	if ad.PriceMonth, err = ToDecimal(priceMonth); err != nil {
+		return nil, err
+	}
+
+	return &ad, nil
+}
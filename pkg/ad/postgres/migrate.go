@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+const (
+	// migrateLockID is key of advisory lock, it prevents parallel migrations of several instances
+	migrateLockID = 7283490115
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies migrations from migrations dir which are not applied yet,
+// each migration is applied in its own transaction
+func Migrate(ctx context.Context, conn *pgxpool.Pool) ([]string, error) {
+	c, err := conn.Acquire(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate: acquire connection")
+	}
+	defer c.Release()
+
+	if _, err = c.Exec(ctx, "SELECT pg_advisory_lock($1)", migrateLockID); err != nil {
+		return nil, errors.Wrap(err, "migrate: lock")
+	}
+	defer func() {
+		_, _ = c.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrateLockID)
+	}()
+
+	_, err = c.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migration (
+		version    text        PRIMARY KEY,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate: create schema_migration")
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate: list migrations")
+	}
+	sort.Strings(files)
+
+	applied := make([]string, 0)
+	for _, file := range files {
+		version := strings.TrimSuffix(strings.TrimPrefix(file, "migrations/"), ".sql")
+
+		var exists bool
+		err = c.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migration WHERE version = $1)", version).
+			Scan(&exists)
+		if err != nil {
+			return applied, errors.Wrap(err, fmt.Sprintf("migrate: check version %s", version))
+		}
+		if exists {
+			continue
+		}
+
+		sql, errRead := migrations.ReadFile(file)
+		if errRead != nil {
+			return applied, errors.Wrap(errRead, fmt.Sprintf("migrate: read %s", file))
+		}
+
+		err = pgx.BeginFunc(ctx, c, func(tx pgx.Tx) error {
+			if _, errExec := tx.Exec(ctx, string(sql)); errExec != nil {
+				return errExec
+			}
+			_, errExec := tx.Exec(ctx, "INSERT INTO schema_migration (version) VALUES ($1)", version)
+
+			return errExec
+		})
+		if err != nil {
+			return applied, errors.Wrap(err, fmt.Sprintf("migrate: apply %s", version))
+		}
+		applied = append(applied, version)
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,92 @@
+-- streets are stored without type words, type is resolved by aliases of street_type
+CREATE TABLE street_type (
+    id       smallint PRIMARY KEY,
+    short    text     NOT NULL,
+    aliases  text[]   NOT NULL DEFAULT '{}',
+    in_start boolean  NOT NULL DEFAULT true
+);
+
+INSERT INTO street_type (id, short, aliases, in_start) VALUES
+    (0, '',      '{}',                                   true),
+    (1, 'ул.',   '{улица,ул.,ул}',                       true),
+    (2, 'пр-т',  '{проспект,пр-т,просп.,пр.}',           true),
+    (3, 'пер.',  '{переулок,пер.,пер}',                  true),
+    (4, 'б-р',   '{бульвар,б-р}',                        true),
+    (5, 'пр-д',  '{проезд,пр-д}',                        true),
+    (6, 'тр.',   '{тракт,тр.}',                          true),
+    (7, 'пл.',   '{площадь,пл.}',                        true),
+    (8, 'туп.',  '{тупик,туп.}',                         true);
+
+CREATE TABLE street (
+    id   bigserial PRIMARY KEY,
+    name text      NOT NULL,
+    type smallint  NOT NULL DEFAULT 0 REFERENCES street_type (id),
+    UNIQUE (name, type)
+);
+
+CREATE TABLE ad (
+    id          bigserial        PRIMARY KEY,
+    ext_id      bigint           NOT NULL UNIQUE,
+    c_time      timestamptz,
+    u_time      timestamptz      NOT NULL,
+    url         text             NOT NULL,
+    street_id   bigint           REFERENCES street (id),
+    house       text,
+    loc_lat     double precision,
+    loc_long    double precision,
+    price       numeric,
+    price_m2    numeric,
+    rooms       smallint,
+    floor       smallint,
+    floors      smallint,
+    year        smallint,
+    photos      text[]           NOT NULL DEFAULT '{}',
+    m2_main     double precision,
+    m2_living   double precision,
+    m2_kitchen  double precision,
+    bathroom    text,
+    profile     smallint         NOT NULL,
+    listing     smallint         NOT NULL DEFAULT 1,
+    price_month numeric,
+    rent_period smallint,
+    owner       boolean,
+    agency      text,
+    region      text             NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ad_profile_u_time ON ad (profile, u_time);
+CREATE INDEX ad_street_house ON ad (street_id, house);
+
+CREATE TABLE ad_price (
+    id        bigserial   PRIMARY KEY,
+    ad_id     bigint      NOT NULL REFERENCES ad (id) ON DELETE CASCADE,
+    c_time    timestamptz NOT NULL,
+    price_old numeric,
+    price_new numeric,
+    profile   smallint    NOT NULL
+);
+
+CREATE INDEX ad_price_ad ON ad_price (ad_id);
+CREATE INDEX ad_price_c_time ON ad_price (c_time);
+
+CREATE TABLE subscription (
+    id            bigserial        PRIMARY KEY,
+    tg_id         bigint           NOT NULL,
+    c_time        timestamptz      NOT NULL,
+    street_id     bigint           REFERENCES street (id),
+    house         text,
+    price_from    numeric,
+    price_to      numeric,
+    price_m2_from numeric,
+    price_m2_to   numeric,
+    rooms_from    smallint,
+    rooms_to      smallint,
+    floor_from    smallint,
+    floor_to      smallint,
+    year_from     smallint,
+    year_to       smallint,
+    m2_main_from  double precision,
+    m2_main_to    double precision
+);
+
+CREATE INDEX subscription_tg_id ON subscription (tg_id, id);
@@ -0,0 +1,8 @@
+-- ads of the same apartment from different sources share group id,
+-- group id of ads saved before it is ext id of ad, it is rehashed by migration of source id
+ALTER TABLE ad ADD COLUMN group_id bigint;
+UPDATE ad SET group_id = ext_id;
+ALTER TABLE ad ALTER COLUMN group_id SET NOT NULL;
+
+CREATE INDEX ad_group_idx ON ad (group_id);
+CREATE INDEX ad_candidate_idx ON ad (street_id, house, floor, rooms, listing);
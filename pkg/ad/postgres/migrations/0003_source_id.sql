@@ -0,0 +1,7 @@
+-- ads are keyed by profile and native id of ad in source, ext_id is crc32 of url
+-- and it is not unique, source_id of old ads is set by migrate command or on next put
+ALTER TABLE ad ADD COLUMN source_id text;
+ALTER TABLE ad DROP CONSTRAINT ad_ext_id_key;
+
+CREATE INDEX ad_ext_idx ON ad (ext_id) WHERE source_id IS NULL;
+CREATE UNIQUE INDEX ad_source_idx ON ad (profile, source_id);
@@ -0,0 +1,9 @@
+-- attributes of detail page of ad, seller is model.SellerType
+ALTER TABLE ad ADD COLUMN description    text;
+ALTER TABLE ad ADD COLUMN seller         smallint;
+ALTER TABLE ad ADD COLUMN phone_hidden   boolean;
+ALTER TABLE ad ADD COLUMN material       text;
+ALTER TABLE ad ADD COLUMN ceiling_height double precision;
+ALTER TABLE ad ADD COLUMN balcony        text;
+ALTER TABLE ad ADD COLUMN renovation     text;
+ALTER TABLE ad ADD COLUMN parking        text;
@@ -0,0 +1,8 @@
+-- search state of profile saved after each page, next is pagination data of profile
+CREATE TABLE checkpoint (
+    profile smallint PRIMARY KEY,
+    num     integer     NOT NULL,
+    next    jsonb       NOT NULL,
+    started timestamptz NOT NULL,
+    u_time  timestamptz NOT NULL
+);
@@ -0,0 +1,9 @@
+-- property_type is model.PropertyType, ads saved before it are flats and houses of realt and kufar,
+-- houses are found by their urls, onliner parsed only flats
+ALTER TABLE ad ADD COLUMN property_type smallint NOT NULL DEFAULT 1;
+ALTER TABLE ad ADD COLUMN m2_land       double precision;
+UPDATE ad SET property_type = 2
+WHERE url LIKE '%realt.by/sale-cottages/%' OR url LIKE '%re.kufar.by/%/dom/%';
+
+DROP INDEX ad_candidate_idx;
+CREATE INDEX ad_candidate_idx ON ad (street_id, house, floor, rooms, listing, property_type);
@@ -0,0 +1,3 @@
+-- checkpoint of incremental search is resumed by incremental search only, as it skips pages
+-- which clean run must search, checkpoints saved before it are of full search
+ALTER TABLE checkpoint ADD COLUMN incremental boolean NOT NULL DEFAULT false;
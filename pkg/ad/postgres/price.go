@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+)
+
+const (
+	batchLimitDrops = 10000
+	priceColumns    = "id, ad_id, c_time, price_old::text, price_new::text, profile"
+)
+
+func PricePut(ctx context.Context, conn Conn, price *model.AdPriceTnt) error {
+	_, err := conn.Exec(ctx, `INSERT INTO ad_price (ad_id, c_time, price_old, price_new, profile)
+		VALUES ($1, $2, $3, $4, $5)`,
+		price.AdID, Time(price.Created), Numeric(price.PriceOld), Numeric(price.PriceNew), price.Profile)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("price put: ad id %d", price.AdID))
+	}
+
+	return nil
+}
+
+func PriceHistory(ctx context.Context, conn Conn, adID uint64) ([]*model.AdPriceTnt, error) {
+	rows, err := conn.Query(ctx, "SELECT "+priceColumns+" FROM ad_price WHERE ad_id = $1 ORDER BY id", adID)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("price history: ad select %d", adID))
+	}
+
+	prices, err := scanPrices(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("price history: ad scan %d", adID))
+	}
+
+	return prices, nil
+}
+
+func PriceDrops(ctx context.Context, conn Conn, timeFrom, timeTo time.Time) ([]*model.AdPriceTnt, error) {
+	var after uint64
+	prices := make([]*model.AdPriceTnt, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return prices, nil
+		default:
+		}
+
+		rows, err := conn.Query(ctx, "SELECT "+priceColumns+` FROM ad_price
+			WHERE c_time >= $1 AND c_time < $2 AND price_new < price_old AND id > $3
+			ORDER BY id LIMIT $4`, timeFrom.UTC(), timeTo.UTC(), after, batchLimitDrops)
+		if err != nil {
+			return nil, err
+		}
+
+		batch, err := scanPrices(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		prices = append(prices, batch...)
+		if len(batch) < batchLimitDrops {
+			break
+		}
+		after = batch[len(batch)-1].ID
+	}
+
+	return prices, nil
+}
+
+func scanPrices(rows pgx.Rows) ([]*model.AdPriceTnt, error) {
+	defer rows.Close()
+
+	prices := make([]*model.AdPriceTnt, 0)
+	for rows.Next() {
+		var price model.AdPriceTnt
+		var created time.Time
+		var priceOld, priceNew *string
+		err := rows.Scan(&price.ID, &price.AdID, &created, &priceOld, &priceNew, &price.Profile)
+		if err != nil {
+			return nil, err
+		}
+
+		if price.Created, err = ToDatetime(&created); err != nil {
+			return nil, err
+		}
+		if price.PriceOld, err = ToDecimal(priceOld); err != nil {
+			return nil, err
+		}
+		if price.PriceNew, err = ToDecimal(priceNew); err != nil {
+			return nil, err
+		}
+		prices = append(prices, &price)
+	}
+
+	return prices, rows.Err()
+}
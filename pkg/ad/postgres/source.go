@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+)
+
+const (
+	codeUniqueViolation = "23505"
+)
+
+// AdsBySource returns saved ads of profile with the given source ids, unknown source ids are skipped
+func AdsBySource(ctx context.Context, conn Conn, profileID uint16, sourceIDs []string) ([]*model.AdTnt, error) {
+	rows, err := conn.Query(ctx, "SELECT "+adTntColumns+" FROM ad WHERE profile = $1 AND source_id = ANY($2)",
+		profileID, sourceIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "ads by source: select")
+	}
+
+	ads, err := scanAds(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "ads by source: scan")
+	}
+
+	return ads, nil
+}
+
+// AdsWithoutSourceID returns ads saved before source id ordered by id
+func AdsWithoutSourceID(ctx context.Context, conn Conn, after uint64, limit int) ([]*model.AdSourceTnt, error) {
+	rows, err := conn.Query(ctx, `SELECT id, ext_id, profile, url FROM ad
+		WHERE source_id IS NULL AND id > $1 ORDER BY id LIMIT $2`, int64(after), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "ads without source id: select")
+	}
+
+	ads, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.AdSourceTnt, error) {
+		ad := &model.AdSourceTnt{}
+		var extID int64
+		err := row.Scan(&ad.ID, &extID, &ad.Profile, &ad.URL)
+		ad.ExtID = uint32(extID)
+
+		return ad, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ads without source id: scan")
+	}
+
+	return ads, nil
+}
+
+// AdSetSourceIDs sets source ids of ads saved before it, it must not be called in transaction
+func AdSetSourceIDs(ctx context.Context, conn Conn, ads []*model.AdSourceTnt) error {
+	for _, ad := range ads {
+		if err := adSetSourceID(ctx, conn, ad); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// adSetSourceID sets source id of ad and moves ads of group keyed by ext id of ad to group id,
+// the ad is deleted when ad with the same source id is saved already as it is the same ad of source
+func adSetSourceID(ctx context.Context, conn Conn, ad *model.AdSourceTnt) error {
+	_, err := conn.Exec(ctx, "UPDATE ad SET source_id = $2 WHERE id = $1", int64(ad.ID), ad.SourceID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation {
+		_, err = conn.Exec(ctx, "DELETE FROM ad WHERE id = $1", int64(ad.ID))
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("set source id: delete duplicate %d", ad.ID))
+		}
+
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("set source id: update %d", ad.ID))
+	}
+
+	_, err = conn.Exec(ctx, "UPDATE ad SET group_id = $2 WHERE group_id = $1", int64(ad.ExtID), int64(ad.GroupID))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("set source id: group %d", ad.ID))
+	}
+
+	return nil
+}
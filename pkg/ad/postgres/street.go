@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+const (
+	streetCodeEmpty = "empty_name"
+)
+
+// StreetGetID returns id of street by name with type words, unknown street is created
+func StreetGetID(ctx context.Context, conn Conn, name string) (*street.ID, error) {
+	types, err := StreetGetTypes(ctx, conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "street get id: get types")
+	}
+
+	streetName, streetType := splitStreetType(name, types)
+	if streetName == "" {
+		return &street.ID{
+			Status: http.StatusBadRequest,
+			Code:   streetCodeEmpty,
+		}, nil
+	}
+
+	var id uint64
+	err = conn.QueryRow(ctx, `WITH ins AS (
+			INSERT INTO street (name, type) VALUES ($1, $2)
+			ON CONFLICT (name, type) DO NOTHING
+			RETURNING id
+		)
+		SELECT id FROM ins
+		UNION ALL
+		SELECT id FROM street WHERE name = $1 AND type = $2
+		LIMIT 1`, streetName, streetType).Scan(&id)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("street get id: %s", name))
+	}
+
+	return &street.ID{
+		Status: http.StatusOK,
+		ID:     id,
+	}, nil
+}
+
+func StreetGetTypes(ctx context.Context, conn Conn) (map[uint8]*street.Type, error) {
+	rows, err := conn.Query(ctx, "SELECT id, short, aliases, in_start FROM street_type")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make(map[uint8]*street.Type)
+	for rows.Next() {
+		t := &street.Type{}
+		if err = rows.Scan(&t.ID, &t.Short, &t.Any, &t.InStart); err != nil {
+			return nil, err
+		}
+		types[t.ID] = t
+	}
+
+	return types, rows.Err()
+}
+
+func StreetGet(ctx context.Context, conn Conn, id uint64) (*street.Ext, error) {
+	streetTnt := &model.StreetTnt{}
+	err := conn.QueryRow(ctx, "SELECT id, name, type FROM street WHERE id = $1", id).
+		Scan(&streetTnt.ID, &streetTnt.Name, &streetTnt.Type)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get street id %d: %w", id, model.ErrNotFound)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("get street: select %d", id))
+	}
+
+	types, err := StreetGetTypes(ctx, conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "get street: get types")
+	}
+
+	return &street.Ext{
+		Street: streetTnt,
+		Type:   types[streetTnt.Type],
+	}, nil
+}
+
+// splitStreetType cuts type word from start or end of street name
+func splitStreetType(name string, types map[uint8]*street.Type) (string, uint8) {
+	name = strings.Join(strings.Fields(name), " ")
+	lower := strings.ToLower(name)
+	ids := make([]uint8, 0, len(types))
+	for id := range types {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	for _, id := range ids {
+		for _, alias := range types[id].Any {
+			alias = strings.ToLower(alias)
+			if rest, ok := strings.CutPrefix(lower, alias+" "); ok {
+				return strings.TrimSpace(name[len(name)-len(rest):]), id
+			}
+			if rest, ok := strings.CutSuffix(lower, " "+alias); ok {
+				return strings.TrimSpace(name[:len(rest)]), id
+			}
+		}
+	}
+
+	return name, 0
+}
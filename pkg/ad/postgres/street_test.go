@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/sku4/ad-parser/pkg/ad/street"
+)
+
+func TestSplitStreetType(t *testing.T) {
+	types := map[uint8]*street.Type{
+		1: {ID: 1, Short: "ул.", Any: []string{"улица", "ул.", "ул"}},
+		2: {ID: 2, Short: "пр-т", Any: []string{"проспект", "пр-т", "пр."}},
+		5: {ID: 5, Short: "пр-д", Any: []string{"проезд", "пр-д"}},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		wantName string
+		wantType uint8
+	}{
+		{"type in start", "улица Притыцкого", "Притыцкого", 1},
+		{"short type in start", "ул. Притыцкого", "Притыцкого", 1},
+		{"upper case type", "Улица  Притыцкого", "Притыцкого", 1},
+		{"type in end", "Независимости проспект", "Независимости", 2},
+		{"similar short types", "пр-д Сурганова", "Сурганова", 5},
+		{"without type", "Притыцкого", "Притыцкого", 0},
+		{"only type", "улица", "улица", 0},
+		{"empty", "  ", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, typ := splitStreetType(tt.input, types)
+			if name != tt.wantName || typ != tt.wantType {
+				t.Errorf("splitStreetType(%q) = %q, %d, want %q, %d",
+					tt.input, name, typ, tt.wantName, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/sku4/ad-parser/pkg/ad/subscription"
+)
+
+const (
+	subscriptionColumns = "id, tg_id, c_time, street_id, house, " +
+		"price_from::text, price_to::text, price_m2_from::text, price_m2_to::text, " +
+		"rooms_from, rooms_to, floor_from, floor_to, year_from, year_to, m2_main_from, m2_main_to"
+)
+
+// SubscriptionFilter returns telegram ids of subscriptions matched by fields of ad,
+// empty condition of subscription matches any value
+func SubscriptionFilter(ctx context.Context, conn Conn, fields map[string]any) ([]int64, error) {
+	rows, err := conn.Query(ctx, `SELECT DISTINCT tg_id FROM subscription
+		WHERE (street_id IS NULL OR street_id = $1::bigint)
+			AND (house IS NULL OR house = $2::text)
+			AND (price_from IS NULL OR price_from <= $3::numeric)
+			AND (price_to IS NULL OR price_to >= $3::numeric)
+			AND (price_m2_from IS NULL OR price_m2_from <= $4::numeric)
+			AND (price_m2_to IS NULL OR price_m2_to >= $4::numeric)
+			AND (rooms_from IS NULL OR rooms_from <= $5::smallint)
+			AND (rooms_to IS NULL OR rooms_to >= $5::smallint)
+			AND (floor_from IS NULL OR floor_from <= $6::smallint)
+			AND (floor_to IS NULL OR floor_to >= $6::smallint)
+			AND (year_from IS NULL OR year_from <= $7::smallint)
+			AND (year_to IS NULL OR year_to >= $7::smallint)
+			AND (m2_main_from IS NULL OR m2_main_from <= $8::double precision)
+			AND (m2_main_to IS NULL OR m2_main_to >= $8::double precision)
+		ORDER BY tg_id`,
+		filterArg(fields["street_id"]), filterArg(fields["house"]),
+		filterArg(fields["price"]), filterArg(fields["price_m2"]),
+		filterArg(fields["rooms"]), filterArg(fields["floor"]),
+		filterArg(fields["year"]), filterArg(fields["m2_main"]))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tgIDs := make([]int64, 0)
+	for rows.Next() {
+		var tgID int64
+		if err = rows.Scan(&tgID); err != nil {
+			return nil, err
+		}
+		tgIDs = append(tgIDs, tgID)
+	}
+
+	return tgIDs, rows.Err()
+}
+
+func SubscriptionGetByTgID(ctx context.Context, conn Conn, tgID int64, limit int, after string) (
+	*subscription.GetByTgIDTnt, error) {
+	var afterID uint64
+	if after != "" {
+		var err error
+		afterID, err = strconv.ParseUint(after, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(model.ErrInternalServerError, "wrong after "+after)
+		}
+	}
+
+	resp := &subscription.GetByTgIDTnt{
+		Status:        http.StatusOK,
+		Subscriptions: make([]*model.SubscriptionTnt, 0, limit),
+	}
+	err := conn.QueryRow(ctx, "SELECT count(*) FROM subscription WHERE tg_id = $1", tgID).Scan(&resp.All)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more row is selected to know whether next page exists
+	rows, err := conn.Query(ctx, "SELECT "+subscriptionColumns+` FROM subscription
+		WHERE tg_id = $1 AND id > $2 ORDER BY id LIMIT $3`, tgID, afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if len(resp.Subscriptions) == limit {
+			last := resp.Subscriptions[len(resp.Subscriptions)-1]
+			resp.After = strconv.FormatUint(last.ID, 10)
+			break
+		}
+
+		sub, errScan := scanSubscription(rows)
+		if errScan != nil {
+			return nil, errScan
+		}
+		resp.Subscriptions = append(resp.Subscriptions, sub)
+	}
+
+	return resp, rows.Err()
+}
+
+func scanSubscription(rows interface{ Scan(dest ...any) error }) (*model.SubscriptionTnt, error) {
+	var sub model.SubscriptionTnt
+	var created time.Time
+	var priceFrom, priceTo, priceM2From, priceM2To *string
+	err := rows.Scan(&sub.ID, &sub.TelegramID, &created, &sub.StreetID, &sub.House,
+		&priceFrom, &priceTo, &priceM2From, &priceM2To,
+		&sub.RoomsFrom, &sub.RoomsTo, &sub.FloorFrom, &sub.FloorTo,
+		&sub.YearFrom, &sub.YearTo, &sub.M2MainFrom, &sub.M2MainTo)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Created, err = ToDatetime(&created); err != nil {
+		return nil, err
+	}
+	if sub.PriceFrom, err = ToDecimal(priceFrom); err != nil {
+		return nil, err
+	}
+	if sub.PriceTo, err = ToDecimal(priceTo); err != nil {
+		return nil, err
+	}
+	if sub.PriceM2From, err = ToDecimal(priceM2From); err != nil {
+		return nil, err
+	}
+	if sub.PriceM2To, err = ToDecimal(priceM2To); err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
@@ -0,0 +1,12 @@
+package price
+
+import (
+	"github.com/sku4/ad-parser/pkg/ad/model"
+)
+
+type DropsTnt struct {
+	Status int                 `mapstructure:"status"`
+	Code   string              `mapstructure:"code"`
+	After  string              `mapstructure:"after"`
+	Prices []*model.AdPriceTnt `mapstructure:"prices"`
+}
@@ -0,0 +1,98 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/sku4/ad-parser/pkg/ad/model"
+	"github.com/tarantool/go-tarantool/v2"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/pool"
+)
+
+const (
+	batchLimitDrops = 10000
+)
+
+func Put(ctx context.Context, conn pool.Pooler, price *model.AdPriceTnt) error {
+	req := tarantool.NewInsertRequest(model.SpaceAdPrice).
+		Tuple(price.ConvertToInsertTuple()).
+		Context(ctx)
+	_, err := conn.Do(req, pool.RW).Get()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("price put: ad id %d", price.AdID))
+	}
+
+	return nil
+}
+
+func History(ctx context.Context, conn pool.Pooler, adID uint64) ([]*model.AdPriceTnt, error) {
+	var pricesTnt []*model.AdPriceTnt
+	req := tarantool.NewSelectRequest(model.SpaceAdPrice).
+		Index(model.IndexAd).
+		Iterator(tarantool.IterEq).
+		Key(tarantool.UintKey{I: uint(adID)}).
+		Context(ctx)
+	err := conn.Do(req, pool.PreferRO).GetTyped(&pricesTnt)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("price history: ad select %d", adID))
+	}
+
+	return pricesTnt, nil
+}
+
+func Drops(ctx context.Context, conn pool.Pooler, timeFrom, timeTo time.Time) ([]*model.AdPriceTnt, error) {
+	timeFromTnt, err := datetime.NewDatetime(timeFrom.UTC())
+	if err != nil {
+		return nil, err
+	}
+	timeToTnt, err := datetime.NewDatetime(timeTo.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	var after string
+	prices := make([]*model.AdPriceTnt, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return prices, nil
+		default:
+		}
+
+		call := tarantool.NewCallRequest("ad_price.drops").
+			Args([]interface{}{timeFromTnt, timeToTnt, batchLimitDrops, after}).
+			Context(ctx)
+		resp, errCall := conn.Do(call, pool.PreferRO).Get()
+		if errCall != nil {
+			return nil, errCall
+		}
+
+		var dropsTnt []*DropsTnt
+		err = mapstructure.Decode(resp.Data, &dropsTnt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(dropsTnt) == 0 {
+			return nil, model.ErrParseResponse
+		}
+		dropTnt := dropsTnt[0]
+
+		if dropTnt.Status != http.StatusOK {
+			return nil, errors.Wrap(model.ErrInternalServerError, dropTnt.Code)
+		}
+
+		after = dropTnt.After
+		prices = append(prices, dropTnt.Prices...)
+		if after == "" {
+			break
+		}
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,18 @@
+package profile
+
+// built-in profiles, they are registered for every client of pkg/ad
+// and parsers of service are registered by their id and code
+var (
+	Kufar = Profile{ID: 1, Code: "kufar", Name: "Kufar", BaseURL: "https://re.kufar.by", Enabled: true,
+		Locality: true}
+	Onliner  = Profile{ID: 2, Code: "onliner", Name: "Onliner", BaseURL: "https://r.onliner.by", Enabled: true}
+	Realt    = Profile{ID: 3, Code: "realt", Name: "Realt", BaseURL: "https://realt.by", Enabled: true}
+	Hata     = Profile{ID: 4, Code: "hata", Name: "Hata", BaseURL: "https://www.hata.by", Enabled: true}
+	Domovita = Profile{ID: 5, Code: "domovita", Name: "Domovita", BaseURL: "https://domovita.by", Enabled: true}
+)
+
+func init() {
+	for _, p := range []Profile{Kufar, Onliner, Realt, Hata, Domovita} {
+		MustRegister(&p)
+	}
+}
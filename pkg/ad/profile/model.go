@@ -1,6 +1,14 @@
 package profile
 
+// Profile is source of ads, disabled profile is kept in registry
+// to resolve its saved ads but it is not parsed
 type Profile struct {
-	ID   uint16 `json:"id"`
-	Code string `json:"code"`
+	ID      uint16 `json:"id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+	Enabled bool   `json:"enabled"`
+	// Locality is set for profile which searches ads by locality of region,
+	// regions of such profile must have locality
+	Locality bool `json:"locality"`
 }
@@ -2,50 +2,144 @@ package profile
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
 )
 
 var (
-	profilesIDs   map[uint16]string
-	profilesCodes map[string]uint16
-	profiles      = []*Profile{
-		{1, "kufar"},
-		{2, "onliner"},
-		{3, "realt"},
-	}
+	ErrProfileExists   = errors.New("profile is registered already")
+	ErrProfileConflict = errors.New("profile id and code do not match registered profile")
+	ErrProfileInvalid  = errors.New("profile must have id and code")
 )
 
-func GetByCode(ctx context.Context, code string) uint16 {
-	if i, ok := profilesCodes[code]; ok {
-		return i
+// registry of profiles, it keeps built-in profiles and profiles registered or declared by config
+var (
+	mu            sync.RWMutex
+	profilesIDs   = make(map[uint16]*Profile)
+	profilesCodes = make(map[string]*Profile)
+)
+
+// Register adds profile to registry, id and code of profile must be unique
+func Register(p *Profile) error {
+	if p.ID == 0 || p.Code == "" {
+		return fmt.Errorf("register profile %d '%s': %w", p.ID, p.Code, ErrProfileInvalid)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := profilesIDs[p.ID]; ok {
+		return fmt.Errorf("register profile %d: %w", p.ID, ErrProfileExists)
 	}
-	fill(ctx)
-	if i, ok := profilesCodes[code]; ok {
-		return i
+	if _, ok := profilesCodes[p.Code]; ok {
+		return fmt.Errorf("register profile '%s': %w", p.Code, ErrProfileExists)
 	}
 
-	return 0
+	c := *p
+	profilesIDs[c.ID] = &c
+	profilesCodes[c.Code] = &c
+
+	return nil
 }
 
-func GetByID(ctx context.Context, id uint16) string {
-	if s, ok := profilesIDs[id]; ok {
-		return s
+// MustRegister adds profile to registry and panics on error
+func MustRegister(p *Profile) {
+	if err := Register(p); err != nil {
+		panic(err)
 	}
-	fill(ctx)
-	if s, ok := profilesIDs[id]; ok {
-		return s
+}
+
+// Declare registers profile declared by config, name, base url and enabled state
+// of registered profile with the same id and code are replaced
+func Declare(p *Profile) error {
+	mu.Lock()
+	registered, okID := profilesIDs[p.ID]
+	_, okCode := profilesCodes[p.Code]
+	if okID && registered.Code == p.Code {
+		registered.Name, registered.BaseURL, registered.Enabled = p.Name, p.BaseURL, p.Enabled
+		mu.Unlock()
+		return nil
 	}
+	mu.Unlock()
 
-	return ""
+	if okID || okCode {
+		return fmt.Errorf("declare profile %d '%s': %w", p.ID, p.Code, ErrProfileConflict)
+	}
+
+	return Register(p)
+}
+
+// ByCode returns copy of registered profile by code
+func ByCode(code string) (*Profile, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, ok := profilesCodes[code]
+	if !ok {
+		return nil, false
+	}
+	c := *p
+
+	return &c, true
 }
 
-func fill(ctx context.Context) {
-	_ = ctx
+// MustByCode returns copy of registered profile by code and panics for unknown code
+func MustByCode(code string) *Profile {
+	p, ok := ByCode(code)
+	if !ok {
+		panic(fmt.Sprintf("profile '%s' is not registered", code))
+	}
+
+	return p
+}
+
+// ByID returns copy of registered profile by id
+func ByID(id uint16) (*Profile, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, ok := profilesIDs[id]
+	if !ok {
+		return nil, false
+	}
+	c := *p
+
+	return &c, true
+}
 
-	profilesIDs = make(map[uint16]string, len(profiles))
-	profilesCodes = make(map[string]uint16, len(profiles))
+// List returns copies of registered profiles ordered by id
+func List() []*Profile {
+	mu.RLock()
+	defer mu.RUnlock()
 
-	for _, p := range profiles {
-		profilesIDs[p.ID] = p.Code
-		profilesCodes[p.Code] = p.ID
+	profiles := make([]*Profile, 0, len(profilesIDs))
+	for _, p := range profilesIDs {
+		c := *p
+		profiles = append(profiles, &c)
 	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+
+	return profiles
+}
+
+// GetByCode returns id of profile by code, zero is returned for unknown code
+func GetByCode(_ context.Context, code string) uint16 {
+	if p, ok := ByCode(code); ok {
+		return p.ID
+	}
+
+	return 0
+}
+
+// GetByID returns code of profile by id, empty string is returned for unknown id
+func GetByID(_ context.Context, id uint16) string {
+	if p, ok := ByID(id); ok {
+		return p.Code
+	}
+
+	return ""
 }
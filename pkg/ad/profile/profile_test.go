@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	MustRegister(&Profile{ID: 99, Code: "test99"})
+
+	tests := []struct {
+		name    string
+		profile *Profile
+		err     error
+	}{
+		{"new profile", &Profile{ID: 100, Code: "test100", Enabled: true}, nil},
+		{"registered id", &Profile{ID: 99, Code: "test101"}, ErrProfileExists},
+		{"registered code", &Profile{ID: 101, Code: "test99"}, ErrProfileExists},
+		{"without id", &Profile{Code: "test102"}, ErrProfileInvalid},
+		{"without code", &Profile{ID: 102}, ErrProfileInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Register(tt.profile); !errors.Is(err, tt.err) {
+				t.Fatalf("register error %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	if p, ok := ByID(100); !ok || p.Code != "test100" || GetByCode(context.Background(), "test100") != 100 {
+		t.Errorf("registered profile is not found: %+v", p)
+	}
+	if _, ok := ByCode("test101"); ok {
+		t.Error("profile with registered id is added")
+	}
+}
+
+func TestDeclare(t *testing.T) {
+	if err := Declare(&Profile{ID: 110, Code: "test110", Name: "Test", Enabled: true}); err != nil {
+		t.Fatalf("declare new profile: %s", err)
+	}
+	if err := Declare(&Profile{ID: 110, Code: "test110", Name: "Renamed", BaseURL: "https://test.by"}); err != nil {
+		t.Fatalf("declare registered profile: %s", err)
+	}
+	p := MustByCode("test110")
+	if p.Name != "Renamed" || p.BaseURL != "https://test.by" || p.Enabled {
+		t.Errorf("declared profile is not replaced: %+v", p)
+	}
+
+	for _, conflict := range []*Profile{
+		{ID: 110, Code: "test111"},
+		{ID: 111, Code: "test110"},
+	} {
+		if err := Declare(conflict); !errors.Is(err, ErrProfileConflict) {
+			t.Errorf("declare %+v error %v, want conflict", conflict, err)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	for _, p := range []*Profile{{ID: 122, Code: "test122"}, {ID: 120, Code: "test120"}, {ID: 121, Code: "test121"}} {
+		MustRegister(p)
+	}
+
+	profiles := List()
+	if len(profiles) < 3 || profiles[0].Code != Kufar.Code || profiles[1].Code != Onliner.Code ||
+		profiles[2].Code != Realt.Code {
+		t.Fatalf("unexpected profiles %+v", profiles)
+	}
+	for i := 1; i < len(profiles); i++ {
+		if profiles[i-1].ID >= profiles[i].ID {
+			t.Fatalf("profiles are not ordered by id: %d before %d", profiles[i-1].ID, profiles[i].ID)
+		}
+	}
+}
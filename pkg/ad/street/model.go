@@ -8,6 +8,12 @@ type ID struct {
 	ID     uint64 `mapstructure:"id"`
 }
 
+type IDs struct {
+	Status int               `mapstructure:"status"`
+	Code   string            `mapstructure:"code"`
+	IDs    map[string]uint64 `mapstructure:"ids"`
+}
+
 type Types struct {
 	Status int     `mapstructure:"status"`
 	Code   string  `mapstructure:"code"`
@@ -3,6 +3,7 @@ package street
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
@@ -33,6 +34,31 @@ func GetID(ctx context.Context, conn pool.Pooler, name string) (*ID, error) {
 	return streetIDTnt[0], nil
 }
 
+// GetIDs returns ids of streets by names in one call, unknown streets are absent in result
+func GetIDs(ctx context.Context, conn pool.Pooler, names []string) (map[string]uint64, error) {
+	call := tarantool.NewCallRequest("street.get_ids").Args([]interface{}{names}).Context(ctx)
+	resp, err := conn.Do(call, pool.RW).Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var streetIDsTnt []*IDs
+	err = mapstructure.Decode(resp.Data, &streetIDsTnt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(streetIDsTnt) == 0 {
+		return nil, model.ErrParseResponse
+	}
+
+	if streetIDsTnt[0].Status != http.StatusOK {
+		return nil, errors.Wrap(model.ErrInternalServerError, streetIDsTnt[0].Code)
+	}
+
+	return streetIDsTnt[0].IDs, nil
+}
+
 func GetTypes(ctx context.Context, conn pool.Pooler) (map[uint8]*Type, error) {
 	_ = ctx
 
@@ -0,0 +1,98 @@
+-- Schema and procedures of ad-parser in Tarantool.
+--
+-- Instance loads module after box.cfg, space ad and procedures street.get_id,
+-- street.get_types, ad.clean and ad.filter of instance must exist already:
+--
+--     package.path = '/opt/ad-parser/tarantool/?.lua;' .. package.path
+--     require('adparser').init()
+--
+-- Migrations of migrations dir are applied once in order of their names on writable instance,
+-- procedures of procedures dir are defined on each instance on every start.
+
+local fio = require('fio')
+local fiber = require('fiber')
+local log = require('log')
+
+local dir = fio.dirname(debug.getinfo(1, 'S').source:gsub('^@', ''))
+
+-- yieldEvery is count of tuples updated by migration between yields
+local yieldEvery = 1000
+
+local M = {}
+
+-- add_fields appends fields {name, type} to format of space, fields are nullable
+-- as tuples saved before them have no values, fields of format are not added again
+function M.add_fields(space, fields)
+    local format = space:format()
+    local names = {}
+    for _, f in ipairs(format) do
+        names[f.name] = true
+    end
+    for _, f in ipairs(fields) do
+        if not names[f[1]] then
+            table.insert(format, { name = f[1], type = f[2], is_nullable = true })
+        end
+    end
+    space:format(format)
+end
+
+-- set replaces tuple of space by tuple with values of fields by name, fields which
+-- are out of tuple saved before they were added are set too
+function M.set(space, tuple, values)
+    local m = tuple:tomap({ names_only = true })
+    for k, v in pairs(values) do
+        m[k] = v
+    end
+
+    return space:replace(space:frommap(m))
+end
+
+-- each calls fn for each tuple of space with yield after each yieldEvery tuples,
+-- tuples are collected by primary key first, so fn may update keys of indexes
+function M.each(space, fn)
+    local keys = {}
+    for _, t in space:pairs() do
+        table.insert(keys, t[1])
+    end
+    for i, key in ipairs(keys) do
+        local t = space:get(key)
+        if t ~= nil then
+            fn(t)
+        end
+        if i % yieldEvery == 0 then
+            fiber.yield()
+        end
+    end
+end
+
+-- migrate applies migrations of migrations dir which are not applied yet,
+-- read only instance gets them by replication
+function M.migrate()
+    if box.info.ro then
+        return
+    end
+
+    local files = fio.glob(fio.pathjoin(dir, 'migrations', '*.lua'))
+    table.sort(files)
+    for _, file in ipairs(files) do
+        local version = fio.basename(file, '.lua')
+        box.once('ad-parser:' .. version, function()
+            log.info('ad-parser: apply migration %s', version)
+            assert(loadfile(file))(M)
+        end)
+    end
+end
+
+-- load defines procedures, tables street, ad and ad_price of instance are extended
+function M.load()
+    for _, name in ipairs({ 'street', 'ad', 'ad_price' }) do
+        assert(loadfile(fio.pathjoin(dir, 'procedures', name .. '.lua')))(M)
+    end
+end
+
+function M.init()
+    M.migrate()
+    M.load()
+end
+
+return M
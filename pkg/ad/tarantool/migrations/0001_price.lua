@@ -0,0 +1,16 @@
+-- price history of ads, price_old and price_new are prices of ad before and after its update
+local space = box.schema.space.create('ad_price', {
+    if_not_exists = true,
+    format = {
+        { name = 'id', type = 'unsigned' },
+        { name = 'ad_id', type = 'unsigned' },
+        { name = 'c_time', type = 'datetime' },
+        { name = 'price_old', type = 'decimal', is_nullable = true },
+        { name = 'price_new', type = 'decimal', is_nullable = true },
+        { name = 'profile', type = 'unsigned' },
+    },
+})
+box.schema.sequence.create('ad_price_id', { if_not_exists = true })
+space:create_index('primary', { parts = { 'id' }, sequence = 'ad_price_id', if_not_exists = true })
+space:create_index('ad', { parts = { 'ad_id' }, unique = false, if_not_exists = true })
+space:create_index('c_time', { parts = { 'c_time', 'id' }, if_not_exists = true })
@@ -0,0 +1,16 @@
+-- listing is model.Listing, rent_period is model.RentPeriod, ads saved before them are sales
+local m = ...
+
+m.add_fields(box.space.ad, {
+    { 'listing', 'unsigned' },
+    { 'price_month', 'decimal' },
+    { 'rent_period', 'unsigned' },
+    { 'owner', 'boolean' },
+    { 'agency', 'string' },
+    { 'region', 'string' },
+})
+m.each(box.space.ad, function(t)
+    if t.listing == nil then
+        m.set(box.space.ad, t, { listing = 1 })
+    end
+end)
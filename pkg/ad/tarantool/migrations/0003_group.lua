@@ -0,0 +1,15 @@
+-- ads of the same apartment from different sources share group id,
+-- group id of ads saved before it is ext id of ad, it is rehashed by migration of source id
+local m = ...
+
+m.add_fields(box.space.ad, { { 'group_id', 'unsigned' } })
+m.each(box.space.ad, function(t)
+    if t.group_id == nil then
+        m.set(box.space.ad, t, { group_id = t.ext_id })
+    end
+end)
+box.space.ad:create_index('group', {
+    parts = { { field = 'group_id', is_nullable = true } },
+    unique = false,
+    if_not_exists = true,
+})
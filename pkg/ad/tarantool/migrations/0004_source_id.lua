@@ -0,0 +1,10 @@
+-- ads are keyed by profile and native id of ad in source, ext_id is crc32 of url
+-- and it is not unique, source_id of old ads is set by migrate command or on next put
+local m = ...
+
+m.add_fields(box.space.ad, { { 'source_id', 'string' } })
+box.space.ad:create_index('source', {
+    parts = { { field = 'profile' }, { field = 'source_id', is_nullable = true } },
+    if_not_exists = true,
+})
+box.space.ad.index.ext:alter({ unique = false })
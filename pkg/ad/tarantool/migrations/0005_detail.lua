@@ -0,0 +1,13 @@
+-- attributes of detail page of ad, seller is model.SellerType
+local m = ...
+
+m.add_fields(box.space.ad, {
+    { 'description', 'string' },
+    { 'seller', 'unsigned' },
+    { 'phone_hidden', 'boolean' },
+    { 'material', 'string' },
+    { 'ceiling_height', 'number' },
+    { 'balcony', 'string' },
+    { 'renovation', 'string' },
+    { 'parking', 'string' },
+})
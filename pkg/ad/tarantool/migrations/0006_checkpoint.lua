@@ -0,0 +1,11 @@
+-- search state of profile saved after each page, next is pagination data of profile in json
+local space = box.schema.space.create('checkpoint', {
+    if_not_exists = true,
+    format = {
+        { name = 'profile', type = 'unsigned' },
+        { name = 'num', type = 'integer' },
+        { name = 'next', type = 'string' },
+        { name = 'started', type = 'datetime' },
+    },
+})
+space:create_index('primary', { parts = { 'profile' }, if_not_exists = true })
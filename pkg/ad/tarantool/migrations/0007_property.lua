@@ -0,0 +1,25 @@
+-- property_type is model.PropertyType, ads saved before it are flats and houses of realt and kufar,
+-- houses are found by their urls, onliner parsed only flats
+local m = ...
+
+m.add_fields(box.space.ad, { { 'property_type', 'unsigned' }, { 'm2_land', 'number' } })
+m.each(box.space.ad, function(t)
+    if t.property_type == nil then
+        local house = t.url:find('realt.by/sale-cottages/', 1, true) ~= nil or
+            t.url:find('^https?://re%.kufar%.by/.*/dom/') ~= nil
+        m.set(box.space.ad, t, { property_type = house and 2 or 1 })
+    end
+end)
+-- ads of the same apartment are searched by ad.candidates among ads of the same property type
+box.space.ad:create_index('candidate', {
+    parts = {
+        { field = 'street_id', is_nullable = true },
+        { field = 'house', is_nullable = true },
+        { field = 'floor', is_nullable = true },
+        { field = 'rooms', is_nullable = true },
+        { field = 'listing', is_nullable = true },
+        { field = 'property_type', is_nullable = true },
+    },
+    unique = false,
+    if_not_exists = true,
+})
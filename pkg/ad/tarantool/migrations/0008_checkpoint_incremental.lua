@@ -0,0 +1,10 @@
+-- checkpoint of incremental search is resumed by incremental search only, as it skips pages
+-- which clean run must search, checkpoints saved before it are of full search
+local m = ...
+
+m.add_fields(box.space.checkpoint, { { 'incremental', 'boolean' } })
+m.each(box.space.checkpoint, function(t)
+    if t.incremental == nil then
+        m.set(box.space.checkpoint, t, { incremental = false })
+    end
+end)
@@ -0,0 +1,205 @@
+-- procedures of ads, ad.clean and ad.filter are defined by instance
+
+local m = ...
+
+ad = ad or {}
+
+-- fields of ad which are set by update of saved ad
+local updateFields = {
+    'u_time', 'street_id', 'house', 'loc_lat', 'loc_long', 'price', 'price_m2', 'rooms', 'floor',
+    'floors', 'year', 'm2_main', 'm2_living', 'm2_kitchen', 'bathroom', 'listing', 'price_month',
+    'rent_period', 'owner', 'agency', 'region', 'group_id', 'source_id', 'property_type', 'm2_land',
+}
+
+-- fields of detail page of ad, they are kept on update by ad which is not detailed
+local detailFields = {
+    'photos', 'description', 'seller', 'phone_hidden', 'material', 'ceiling_height',
+    'balcony', 'renovation', 'parking',
+}
+
+-- extLimit is limit of ads with the same ext id, crc32 of urls collide
+local extLimit = 10
+
+local function value(v)
+    if v == nil then
+        return box.NULL
+    end
+
+    return v
+end
+
+-- priceEqual reports whether prices are equal, no price equals no price only
+local function priceEqual(p1, p2)
+    if p1 == nil or p2 == nil then
+        return p1 == nil and p2 == nil
+    end
+
+    return p1 == p2
+end
+
+-- saved returns saved ad of profile by source id, ad saved before source id
+-- is found by ext id and url
+local function saved(t, profile)
+    local found = box.space.ad.index.source:get({ profile, t.source_id })
+    if found ~= nil then
+        return found
+    end
+
+    for _, a in ipairs(box.space.ad.index.ext:select({ t.ext_id }, { iterator = 'EQ', limit = extLimit })) do
+        if a.source_id == nil and a.profile == profile and a.url == t.url then
+            return a
+        end
+    end
+
+    return nil
+end
+
+-- put saves one ad of batch and returns its result, price history of updated ad is saved
+-- in the same transaction when its price is changed
+local function put(t, profile, detailed)
+    local old = saved(t, profile)
+    if old == nil then
+        t.id = nil
+        t.profile = profile
+        if t.c_time == nil then
+            t.c_time = t.u_time
+        end
+        local new = box.space.ad:insert(box.space.ad:frommap(t))
+
+        return { id = new.id, source_id = t.source_id, status = 200, code = 'ok', new = true }
+    end
+
+    local values = {}
+    for _, name in ipairs(updateFields) do
+        values[name] = value(t[name])
+    end
+    if t.group_id == nil then
+        values.group_id = old.group_id
+    end
+    if detailed then
+        for _, name in ipairs(detailFields) do
+            values[name] = value(t[name])
+        end
+    end
+    m.set(box.space.ad, old, values)
+    if not priceEqual(old.price, t.price) then
+        box.space.ad_price:insert({ nil, old.id, t.u_time, value(old.price), value(t.price), profile })
+    end
+
+    return {
+        id = old.id, source_id = t.source_id, status = 200, code = 'ok', new = false, price_old = old.price,
+    }
+end
+
+-- ad.put_batch(tuples, profile, detailed) inserts new ads and updates saved ones found by profile
+-- and source_id or by ext_id and url for ads saved before source_id, saved photos and fields
+-- of detail page are kept when detailed of tuple index is false, it returns {status, code, results}
+-- with result {id, source_id, status, code, new, price_old} for each tuple in order of tuples,
+-- price_old is price of updated ad before update, ad and its price history are saved in one transaction
+function ad.put_batch(tuples, profile, detailed)
+    local results = {}
+    for i, t in ipairs(tuples) do
+        local ok, res = pcall(box.atomic, put, t, profile, detailed[i] == true)
+        if not ok then
+            res = { source_id = t.source_id, status = 500, code = tostring(res), new = false }
+        end
+        results[i] = res
+    end
+
+    return { status = 200, code = 'ok', results = results }
+end
+
+-- ad.candidates(keys) returns {status, code, candidates} with ads of the same street, house,
+-- floor, rooms, listing and property type for each key, ads of profile of key are skipped
+function ad.candidates(keys)
+    local candidates = {}
+    for i, k in ipairs(keys) do
+        local ads = {}
+        local key = { k.street_id, k.house, k.floor, k.rooms, k.listing, k.property_type }
+        for _, a in box.space.ad.index.candidate:pairs(key, { iterator = 'EQ' }) do
+            if a.profile ~= k.profile then
+                table.insert(ads, a:tomap({ names_only = true }))
+            end
+        end
+        candidates[i] = ads
+    end
+
+    return { status = 200, code = 'ok', candidates = candidates }
+end
+
+-- ad.listing(group_id) returns {status, code, ads} with ads of group, status is 404 for unknown group
+function ad.listing(groupID)
+    local ads = {}
+    for _, a in box.space.ad.index.group:pairs({ groupID }, { iterator = 'EQ' }) do
+        table.insert(ads, a:tomap({ names_only = true }))
+    end
+    if #ads == 0 then
+        return { status = 404, code = 'not found', ads = ads }
+    end
+
+    return { status = 200, code = 'ok', ads = ads }
+end
+
+-- ad.by_source(profile, source_ids) returns {status, code, ads} with saved ads of profile
+-- with the given source ids, unknown source ids are skipped
+function ad.by_source(profile, sourceIDs)
+    local ads = {}
+    for _, id in ipairs(sourceIDs) do
+        local a = box.space.ad.index.source:get({ profile, id })
+        if a ~= nil then
+            table.insert(ads, a:tomap({ names_only = true }))
+        end
+    end
+
+    return { status = 200, code = 'ok', ads = ads }
+end
+
+-- ad.without_source_id(after, limit) returns {status, code, ads} with {id, ext_id, profile, url}
+-- of ads without source_id ordered by id
+function ad.without_source_id(after, limit)
+    local ads = {}
+    for _, a in box.space.ad.index.primary:pairs({ after }, { iterator = 'GT' }) do
+        if a.source_id == nil then
+            table.insert(ads, { id = a.id, ext_id = a.ext_id, profile = a.profile, url = a.url })
+            if #ads == limit then
+                break
+            end
+        end
+    end
+
+    return { status = 200, code = 'ok', ads = ads }
+end
+
+-- setSourceID sets source id of ad and moves ads of group keyed by ext id of ad to group id,
+-- the ad is deleted when ad with the same source id is saved already as it is the same ad of source
+local function setSourceID(t)
+    local a = box.space.ad:get(t.id)
+    if a == nil then
+        return
+    end
+
+    local same = box.space.ad.index.source:get({ a.profile, t.source_id })
+    if same ~= nil and same.id ~= a.id then
+        box.space.ad:delete(a.id)
+        return
+    end
+    m.set(box.space.ad, a, { source_id = t.source_id })
+
+    local group = box.space.ad.index.group:select({ t.ext_id })
+    for _, g in ipairs(group) do
+        m.set(box.space.ad, g, { group_id = t.group_id })
+    end
+end
+
+-- ad.set_source_ids(tuples) sets source_id of ads by tuples {id, ext_id, source_id, group_id}
+-- and returns {status, code}
+function ad.set_source_ids(tuples)
+    for _, t in ipairs(tuples) do
+        local ok, err = pcall(box.atomic, setSourceID, t)
+        if not ok then
+            return { status = 500, code = tostring(err) }
+        end
+    end
+
+    return { status = 200, code = 'ok' }
+end
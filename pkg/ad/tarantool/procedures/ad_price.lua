@@ -0,0 +1,31 @@
+-- procedures of price history of ads
+
+ad_price = ad_price or {}
+
+-- ad_price.drops(from, to, limit, after) returns {status, code, after, prices} with prices
+-- dropped in time from from to to ordered by id, after is id of the last price of batch,
+-- it is empty for the last batch
+function ad_price.drops(from, to, limit, after)
+    local prices = {}
+    local first = box.space.ad_price.index.c_time:select({ from }, { iterator = 'GE', limit = 1 })[1]
+    if first == nil then
+        return { status = 200, code = 'ok', after = '', prices = prices }
+    end
+
+    local id = math.max(first.id - 1, tonumber(after) or 0)
+    local last = ''
+    for _, p in box.space.ad_price.index.primary:pairs({ id }, { iterator = 'GT' }) do
+        if p.c_time >= to then
+            break
+        end
+        if p.c_time >= from and p.price_old ~= nil and p.price_new ~= nil and p.price_new < p.price_old then
+            table.insert(prices, p:tomap({ names_only = true }))
+            if #prices == limit then
+                last = tostring(p.id)
+                break
+            end
+        end
+    end
+
+    return { status = 200, code = 'ok', after = last, prices = prices }
+end
@@ -0,0 +1,17 @@
+-- procedures of streets, street.get_id and street.get_types are defined by instance
+
+street = street or {}
+
+-- street.get_ids(names) returns {status, code, ids} where ids maps known street names
+-- to their ids, unknown names are absent
+function street.get_ids(names)
+    local ids = {}
+    for _, name in ipairs(names) do
+        local res = street.get_id(name)
+        if res ~= nil and res.status == 200 then
+            ids[name] = res.id
+        end
+    end
+
+    return { status = 200, code = 'ok', ids = ids }
+end